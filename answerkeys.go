@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// optionLabelPattern 匹配选项开头的字母标号，支持"A."、"A、"、"a："、"(A)"、"（A）"、"A)"等写法
+var optionLabelPattern = regexp.MustCompile(`^\s*(?:[(（]\s*([A-Za-z])\s*[)）]|([A-Za-z])\s*[.．、:：)）])\s*`)
+
+// letterAnswerPattern 匹配只由选项字母组成的答案，如"A"、"AB"、"a,c"、"A、C"
+var letterAnswerPattern = regexp.MustCompile(`^[A-Za-z](?:[\s,，、;；/]*[A-Za-z])*$`)
+
+// optionLabel 提取选项的字母标号（大写）及去掉标号后的选项文本，没有标号时返回空字符串
+func optionLabel(option string) (string, string) {
+	m := optionLabelPattern.FindStringSubmatchIndex(option)
+	if m == nil {
+		return "", strings.TrimSpace(option)
+	}
+	label := ""
+	for i := 2; i < len(m); i += 2 {
+		if m[i] >= 0 {
+			label = strings.ToUpper(option[m[i]:m[i+1]])
+			break
+		}
+	}
+	return label, strings.TrimSpace(option[m[1]:])
+}
+
+// optionKey 按选项顺序对应的选项字母，第0个为A，超过26个选项时返回空字符串
+func optionKey(i int) string {
+	if i < 0 || i >= 26 {
+		return ""
+	}
+	return string(rune('A' + i))
+}
+
+// resolveAnswerKeys 将只写了选项字母的答案解析为完整的选项文本，并记录答案对应的选项字母。
+// 选项带有字母标号时按标号对应；所有选项都没有标号时按顺序视为A、B、C……。
+// 答案与某个选项文本相同时优先按文本对应，"False"、"UDP"这类单词不会被当作选项字母。
+// 答案字母指向不存在的选项时保留原答案，并在Warnings中说明
+func resolveAnswerKeys(item AnswerItem) AnswerItem {
+	return resolveAnswers(item, true)
+}
+
+// resolveAnswerText 只按选项文本解析答案并记录答案对应的选项字母，不把答案当作选项字母。
+// 用于修改题目，答案已经是完整的选项文本，选项调整顺序后按文本重新对应
+func resolveAnswerText(item AnswerItem) AnswerItem {
+	return resolveAnswers(item, false)
+}
+
+// resolveAnswers 解析答案对应的选项，letters为true时识别只写了选项字母的答案
+func resolveAnswers(item AnswerItem, letters bool) AnswerItem {
+	keys := []string{}            // 选项字母，按选项顺序排列
+	byKey := map[string]string{}  // 选项字母 -> 完整选项文本
+	byText := map[string]string{} // 完整或去掉标号的选项文本 -> 选项字母
+	labeled := false
+	for _, option := range item.Options {
+		if label, _ := optionLabel(option); label != "" {
+			labeled = true
+			break
+		}
+	}
+	for i, option := range item.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+		label, text := optionLabel(option)
+		if !labeled && i < 26 {
+			label, text = optionKey(i), option
+		}
+		if label == "" {
+			continue
+		}
+		if _, ok := byKey[label]; !ok {
+			keys = append(keys, label)
+			byKey[label] = option
+		}
+		// 完整文本优先于其他选项去掉标号后的文本
+		byText[option] = label
+		if _, ok := byText[text]; !ok {
+			byText[text] = label
+		}
+	}
+	if len(keys) == 0 {
+		return item
+	}
+
+	// 逐个答案解析出对应的选项字母
+	selected := map[string]bool{}
+	unresolved := []string{}
+	for _, ans := range item.Answer {
+		ans = strings.TrimSpace(ans)
+		if ans == "" {
+			continue
+		}
+
+		// 答案与选项文本相同，或去掉标号后与选项文本相同（选项调整过顺序时标号可能已经不对）
+		if key, ok := byText[ans]; ok {
+			selected[key] = true
+			continue
+		}
+		label, text := optionLabel(ans)
+		if key, ok := byText[text]; ok && label != "" {
+			selected[key] = true
+			continue
+		}
+		if !letters {
+			unresolved = append(unresolved, ans)
+			continue
+		}
+
+		if letterAnswerPattern.MatchString(ans) {
+			if found, missing := answerLetters(ans, byKey); len(missing) == 0 {
+				for _, key := range found {
+					selected[key] = true
+				}
+				continue
+			} else if looksLikeKeys(ans) {
+				item.Warnings = append(item.Warnings, fmt.Sprintf("答案%s对应的选项不存在", strings.Join(missing, "、")))
+			}
+			unresolved = append(unresolved, ans)
+			continue
+		}
+
+		// 答案带有标号，去掉标号后的文本不是选项时按标号对应
+		if label != "" {
+			if _, ok := byKey[label]; ok {
+				selected[label] = true
+			} else {
+				item.Warnings = append(item.Warnings, fmt.Sprintf("答案%s对应的选项不存在", label))
+				unresolved = append(unresolved, ans)
+			}
+			continue
+		}
+		unresolved = append(unresolved, ans)
+	}
+
+	// 已解析的答案按选项顺序替换为完整选项文本，无法解析的答案保留在后面
+	answer := []string{}
+	answerKeys := []string{}
+	for _, key := range keys {
+		if selected[key] {
+			answerKeys = append(answerKeys, key)
+			answer = append(answer, byKey[key])
+		}
+	}
+	if len(answerKeys) == 0 {
+		return item
+	}
+	item.Answer = append(answer, unresolved...)
+	item.AnswerKeys = answerKeys
+	return item
+}
+
+// answerLetters 拆出字母答案中的选项字母（大写），missing为没有对应选项的字母
+func answerLetters(ans string, byKey map[string]string) (found []string, missing []string) {
+	for _, r := range ans {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			continue
+		}
+		key := strings.ToUpper(string(r))
+		if _, ok := byKey[key]; ok {
+			found = append(found, key)
+		} else if !slices.Contains(missing, key) {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// looksLikeKeys 判断字母答案是否像选项字母而不是单词：由分隔开的单个字母组成，
+// 或者全部大写且按字母顺序排列不重复，如"E"、"A,E"、"ABE"。"UDP"、"Rust"视为单词
+func looksLikeKeys(ans string) bool {
+	groups := strings.FieldsFunc(ans, func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < 'a' || r > 'z')
+	})
+	if len(groups) > 1 {
+		for _, group := range groups {
+			if len(group) != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	for i, r := range ans {
+		if r < 'A' || r > 'Z' || (i > 0 && r <= rune(ans[i-1])) {
+			return false
+		}
+	}
+	return true
+}
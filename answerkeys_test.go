@@ -0,0 +1,44 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveAnswerKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []string
+		answer   []string
+		wantAns  []string
+		wantKeys []string
+		warning  bool
+	}{
+		{"字母答案", []string{"A. 北京", "B. 上海", "C. 广州"}, []string{"AC"}, []string{"A. 北京", "C. 广州"}, []string{"A", "C"}, false},
+		{"无标号选项按顺序对应", []string{"北京", "上海"}, []string{"b"}, []string{"上海"}, []string{"B"}, false},
+		{"单词答案按选项文本对应", []string{"A. True", "B. False"}, []string{"False"}, []string{"B. False"}, []string{"B"}, false},
+		{"无标号的单词答案", []string{"Rust", "Go", "Java"}, []string{"Rust"}, []string{"Rust"}, []string{"A"}, false},
+		{"大写单词答案", []string{"TCP", "UDP"}, []string{"UDP"}, []string{"UDP"}, []string{"B"}, false},
+		{"标号与文本不一致时按文本", []string{"A. 上海", "B. 北京"}, []string{"A. 北京"}, []string{"B. 北京"}, []string{"B"}, false},
+		{"字母超出选项", []string{"A. 北京", "B. 上海"}, []string{"E"}, []string{"E"}, nil, true},
+		{"不在选项中的单词不提示字母", []string{"Go", "Java"}, []string{"Rust"}, []string{"Rust"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := resolveAnswerKeys(AnswerItem{Question: "q", Options: tt.options, Answer: tt.answer})
+			if !slices.Equal(item.Answer, tt.wantAns) || !slices.Equal(item.AnswerKeys, tt.wantKeys) {
+				t.Errorf("答案 = %q %q，期望 %q %q", item.Answer, item.AnswerKeys, tt.wantAns, tt.wantKeys)
+			}
+			if (len(item.Warnings) > 0) != tt.warning {
+				t.Errorf("提示 = %q", item.Warnings)
+			}
+		})
+	}
+}
+
+func TestResolveAnswerTextIgnoresLetters(t *testing.T) {
+	item := resolveAnswerText(AnswerItem{Question: "q", Options: []string{"A. 北京", "B. 上海"}, Answer: []string{"B"}})
+	if len(item.AnswerKeys) != 0 || !slices.Equal(item.Answer, []string{"B"}) {
+		t.Errorf("答案 = %q %q，不应按字母解析", item.Answer, item.AnswerKeys)
+	}
+}
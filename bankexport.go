@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 表格导出格式，与题库交换文件的json、jsonl并列
+const (
+	ExportFormatCSV  = "csv"  // UTF-8（带BOM）逗号分隔，Excel可直接打开
+	ExportFormatXLSX = "xlsx" // Excel工作簿
+)
+
+// exportSheetName 导出Excel时的工作表名称
+const exportSheetName = "题库"
+
+// exportSeparatorCandidates 导出时依次尝试的选项和答案分隔符，选择第一个不出现在任何选项和答案中的。
+// 换行排在最前：导入时选项列含换行会被自动推断为按换行拆分
+var exportSeparatorCandidates = []string{"\n", "|", ";", "/", "\t"}
+
+// exportContentTypes 各导出格式的Content-Type
+var exportContentTypes = map[string]string{
+	BankFormatJSON:   "application/json; charset=utf-8",
+	BankFormatJSONL:  "application/x-ndjson; charset=utf-8",
+	ExportFormatCSV:  "text/csv; charset=utf-8",
+	ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ExportResult 导出结果
+type ExportResult struct {
+	FilePath string `json:"filePath"`
+	Format   string `json:"format"`
+	Count    int    `json:"count"` // 导出的题目数量
+
+	// 导出时使用的分隔符，重新导入时传入这两个值即可还原全部题目
+	Separators DetectedSeparators `json:"separators"`
+}
+
+// normalizeExportFormat 校验导出格式，支持csv、xlsx以及题库交换文件的json、jsonl，为空时使用JSON
+func normalizeExportFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatXLSX, "excel":
+		return ExportFormatXLSX, nil
+	}
+	if format, err := normalizeBankFormat(format); err == nil {
+		return format, nil
+	}
+	return "", fmt.Errorf("不支持的导出格式: %s，仅支持csv、xlsx、json和jsonl", format)
+}
+
+// chooseExportSeparator 选择不会与题目内容冲突的选项和答案分隔符。
+// 所有候选都冲突时无法保证重新导入后还原，返回错误
+func chooseExportSeparator(answers []AnswerItem) (string, error) {
+	for _, candidate := range exportSeparatorCandidates {
+		conflict := false
+		for _, item := range answers {
+			if containsSeparator(item.Options, candidate) || containsSeparator(item.Answer, candidate) {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("选项或答案中包含了全部可用的分隔符（换行、|、;、/、制表符），无法导出为表格，请导出为json或jsonl")
+}
+
+// containsSeparator 判断一组文本中是否有包含分隔符的
+func containsSeparator(values []string, separator string) bool {
+	for _, value := range values {
+		if strings.Contains(value, separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// exportHeaders 导出文件的标题行，必需字段在前，可选字段在后
+func exportHeaders() []string {
+	return append(append([]string{}, answerColumns...), optionalAnswerColumns...)
+}
+
+// exportRecord 将一道题转换为与exportHeaders对应的一行
+func exportRecord(item AnswerItem, separator string) []string {
+	return []string{
+		item.Type,
+		item.Question,
+		strings.Join(item.Options, separator),
+		exportAnswerCell(item, separator),
+		item.Explanation,
+		item.Category,
+		item.Difficulty,
+		item.Chapter,
+		strings.Join(item.Tags, exportTagSeparator),
+		item.Source,
+	}
+}
+
+// exportAnswerCell 生成答案单元格，写完整选项文本。导入时答案先按选项文本对应，
+// 选项文本本身形如"A"、"AB"时也能还原
+func exportAnswerCell(item AnswerItem, separator string) string {
+	return strings.Join(item.Answer, separator)
+}
+
+// writeBankCSV 将题库写为CSV，文件开头带UTF-8 BOM以便Excel正确识别中文
+func writeBankCSV(w io.Writer, bank *QuestionBank, separator string) error {
+	if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(w)
+	if err := csvWriter.Write(exportHeaders()); err != nil {
+		return err
+	}
+	for _, item := range bank.Answers {
+		if err := csvWriter.Write(exportRecord(item, separator)); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeBankXLSX 将题库写为Excel工作簿，逐行流式写入工作表
+func writeBankXLSX(w io.Writer, bank *QuestionBank, separator string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), exportSheetName); err != nil {
+		return err
+	}
+	// 选项和答案可能按换行分隔，单元格需要自动换行才能完整显示
+	wrapStyle, err := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	if err != nil {
+		return err
+	}
+	stream, err := f.NewStreamWriter(exportSheetName)
+	if err != nil {
+		return err
+	}
+	if err := stream.SetColWidth(2, 4, 40); err != nil {
+		return err
+	}
+
+	headers := exportHeaders()
+	row := make([]interface{}, len(headers))
+	for i, header := range headers {
+		row[i] = header
+	}
+	if err := stream.SetRow("A1", row); err != nil {
+		return err
+	}
+	for i, item := range bank.Answers {
+		row := []interface{}{}
+		for _, value := range exportRecord(item, separator) {
+			row = append(row, excelize.Cell{StyleID: wrapStyle, Value: value})
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := stream.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+	if err := stream.Flush(); err != nil {
+		return err
+	}
+	return f.Write(w)
+}
+
+// writeBankExport 按导出格式写出题库，返回使用的分隔符（仅CSV和Excel）
+func writeBankExport(w io.Writer, bank *QuestionBank, format string) (DetectedSeparators, error) {
+	separators := DetectedSeparators{}
+	switch format {
+	case ExportFormatCSV, ExportFormatXLSX:
+		separator, err := chooseExportSeparator(bank.Answers)
+		if err != nil {
+			return separators, err
+		}
+		examService := &ExamService{}
+		separators.OptionSeparator = examService.escapeSeparator(separator)
+		separators.AnswerSeparator = separators.OptionSeparator
+		if format == ExportFormatCSV {
+			separators.Delimiter = ","
+			return separators, writeBankCSV(w, bank, separator)
+		}
+		return separators, writeBankXLSX(w, bank, separator)
+	default:
+		return separators, writeBankFile(w, bank, format)
+	}
+}
+
+// exportBank 将题库按指定格式写入文件，bankName为空时导出当前激活的题库
+func (e *ExamService) exportBank(bankName string, filePath string, format string) (ExportResult, error) {
+	bank, err := findBank(bankName)
+	if err != nil {
+		return ExportResult{}, err
+	}
+
+	result := ExportResult{FilePath: filePath, Format: format, Count: len(bank.Answers)}
+	err = writeFileAtomicFunc(filePath, func(w io.Writer) error {
+		separators, err := writeBankExport(w, bank, format)
+		result.Separators = separators
+		return err
+	})
+	if err != nil {
+		return ExportResult{}, fmt.Errorf("导出题库失败: %v", err)
+	}
+	return result, nil
+}
+
+// ExportCSV 将题库导出为CSV文件，标题行和分隔符与ParseCSVFile一致，可原样导入
+func (e *ExamService) ExportCSV(bankName string, filePath string) (ExportResult, error) {
+	return e.exportBank(bankName, filePath, ExportFormatCSV)
+}
+
+// ExportXLSX 将题库导出为Excel文件，标题行和分隔符与ParseExcelFile一致，可原样导入
+func (e *ExamService) ExportXLSX(bankName string, filePath string) (ExportResult, error) {
+	return e.exportBank(bankName, filePath, ExportFormatXLSX)
+}
+
+// handleExportBank 处理HTTP导出题库请求，以附件形式流式返回文件。
+// 查询参数：bank为题库名称（为空时导出当前激活的题库），format为json、jsonl、csv或xlsx。
+// CSV和Excel使用的分隔符通过X-Option-Separator和X-Answer-Separator响应头返回
+func handleExportBank(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition, X-Option-Separator, X-Answer-Separator")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	format, err := normalizeExportFormat(query.Get("format"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	bank, err := findBank(query.Get("bank"))
+	if err != nil {
+		http.Error(w, "导出题库失败: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if format == ExportFormatCSV || format == ExportFormatXLSX {
+		separator, err := chooseExportSeparator(bank.Answers)
+		if err != nil {
+			http.Error(w, "导出题库失败: "+err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		// 创建ExamService实例
+		examService := &ExamService{}
+		separator = examService.escapeSeparator(separator)
+		w.Header().Set("X-Option-Separator", separator)
+		w.Header().Set("X-Answer-Separator", separator)
+	}
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(bank.Name+"."+format))
+
+	// 响应头已发出，写入失败时只能中断连接
+	buffered := bufio.NewWriter(w)
+	if _, err := writeBankExport(buffered, bank, format); err == nil {
+		buffered.Flush()
+	}
+}
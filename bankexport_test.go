@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExportCSVRoundTrip(t *testing.T) {
+	useTempAppData(t)
+	e := &ExamService{}
+
+	items := []AnswerItem{
+		{Type: QuestionTypeSingle, Question: "哪种语言有借用检查", Options: []string{"Go", "Rust"}, Answer: []string{"Rust"}},
+		{Type: QuestionTypeSingle, Question: "无连接的协议", Options: []string{"A. TCP", "B. UDP"}, Answer: []string{"B"}},
+		{Type: QuestionTypeMultiple, Question: "偶数", Options: []string{"A、2", "B、3", "C、4"}, Answer: []string{"AC"}, Explanation: "能被2整除"},
+		{Type: QuestionTypeSingle, Question: "选项就是字母", Options: []string{"B", "A"}, Answer: []string{"A"}},
+		{Type: QuestionTypeBlank, Question: "多行答案, \"引号\"", Answer: []string{"第一行\n第二行"}},
+	}
+	for i := range items {
+		items[i] = normalizeImportedItem(items[i])
+	}
+	if err := e.SetBankAnswers("导出", items); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := e.GetBankAnswers("导出")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "导出.csv")
+	result, err := e.ExportCSV("导出", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := e.ParseCSVFile(path, "auto", result.Separators.OptionSeparator, result.Separators.AnswerSeparator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported.Report.Skipped) > 0 {
+		t.Errorf("跳过了 %d 行: %+v", len(imported.Report.Skipped), imported.Report.Skipped)
+	}
+	got := imported.Items
+	if len(got) != len(stored) {
+		t.Fatalf("重新导入 %d 道题，期望 %d 道", len(got), len(stored))
+	}
+	for i := range got {
+		got[i].Source = ""
+		want := stored[i]
+		want.ID = ""
+		if !reflect.DeepEqual(got[i], want) {
+			t.Errorf("第%d题\n导入 %#v\n期望 %#v", i+1, got[i], want)
+		}
+	}
+}
+
+func TestChooseExportSeparatorConflict(t *testing.T) {
+	items := []AnswerItem{{Question: "q", Options: []string{"a\nb|c;d/e\tf"}, Answer: []string{"a\nb|c;d/e\tf"}}}
+	if _, err := chooseExportSeparator(items); err == nil || !strings.Contains(err.Error(), "分隔符") {
+		t.Errorf("所有分隔符都冲突时应返回错误，得到 %v", err)
+	}
+}
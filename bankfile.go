@@ -0,0 +1,432 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bankFileFormat 题库交换文件的格式标识
+const bankFileFormat = "exam-assistant-bank"
+
+// bankFileVersion 题库交换文件的格式版本
+// 1: 文件头记录题库元数据，题目为AnswerItem
+const bankFileVersion = 1
+
+// 题库交换文件的两种写法
+const (
+	BankFormatJSON  = "json"  // 单个JSON对象，题目在items数组中
+	BankFormatJSONL = "jsonl" // 第一行为文件头，之后每行一道题，适合很大的题库
+)
+
+// BankFileHeader 题库交换文件的文件头
+type BankFileHeader struct {
+	Format     string    `json:"format"`  // 固定为exam-assistant-bank
+	Version    int       `json:"version"` // 格式版本
+	Name       string    `json:"name"`
+	Source     string    `json:"source,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+	ExportedAt time.Time `json:"exportedAt"`
+	Count      int       `json:"count"` // 题目数量，仅供参考，导入时以实际读到的题目为准
+}
+
+// bankFormatFromPath 根据扩展名判断文件写法，.jsonl和.ndjson为JSON Lines，其余按JSON处理
+func bankFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return BankFormatJSONL
+	default:
+		return BankFormatJSON
+	}
+}
+
+// normalizeBankFormat 校验文件写法，为空时使用JSON
+func normalizeBankFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", BankFormatJSON:
+		return BankFormatJSON, nil
+	case BankFormatJSONL, "ndjson":
+		return BankFormatJSONL, nil
+	default:
+		return "", fmt.Errorf("不支持的题库文件格式: %s，仅支持json和jsonl", format)
+	}
+}
+
+// bankFileHeaderOf 生成题库的文件头
+func bankFileHeaderOf(bank *QuestionBank) BankFileHeader {
+	return BankFileHeader{
+		Format:     bankFileFormat,
+		Version:    bankFileVersion,
+		Name:       bank.Name,
+		Source:     bank.Source,
+		CreatedAt:  bank.CreatedAt,
+		UpdatedAt:  bank.UpdatedAt,
+		ExportedAt: time.Now(),
+		Count:      len(bank.Answers),
+	}
+}
+
+// writeBankFile 逐题写出题库，不在内存中拼接整个文件
+func writeBankFile(w io.Writer, bank *QuestionBank, format string) error {
+	header := bankFileHeaderOf(bank)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	if format == BankFormatJSONL {
+		if err := encoder.Encode(header); err != nil {
+			return err
+		}
+		for _, item := range bank.Answers {
+			if err := encoder.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// JSON：在文件头对象的末尾追加items数组，每行一道题
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(headerJSON[:len(headerJSON)-1]); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, ",\"items\":["); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	itemEncoder := json.NewEncoder(&buf)
+	itemEncoder.SetEscapeHTML(false)
+	for i, item := range bank.Answers {
+		buf.Reset()
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+		if err := itemEncoder.Encode(item); err != nil {
+			return err
+		}
+		// Encode会在末尾追加换行，由下一道题的分隔符负责换行
+		buf.Truncate(buf.Len() - 1)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "\n]}\n")
+	return err
+}
+
+// readBankFile 逐题读取题库交换文件，自动识别JSON和JSON Lines写法，
+// 也接受不带文件头的AnswerItem数组。格式错误的题目记入导入报告
+func readBankFile(r io.Reader, strict bool) (BankFileHeader, ImportResult, error) {
+	header := BankFileHeader{}
+	collector := newImportCollector(strict)
+	decoder := json.NewDecoder(bufio.NewReader(r))
+
+	// 报告中的行号：JSON为第几道题，JSON Lines为文件中的行号（文件头占第1行）
+	ordinal := 0
+	readItem := func(lineOffset int) error {
+		ordinal++
+		var item AnswerItem
+		if err := decoder.Decode(&item); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				collector.skip(ordinal+lineOffset, "题目格式错误: "+err.Error())
+				return nil
+			}
+			return fmt.Errorf("解析第%d道题失败: %v", ordinal, err)
+		}
+		collector.add(ordinal+lineOffset, normalizeImportedItem(item))
+		return nil
+	}
+	readArray := func(lineOffset int) error {
+		for decoder.More() {
+			if err := readItem(lineOffset); err != nil {
+				return err
+			}
+		}
+		_, err := decoder.Token() // 数组结束符
+		return err
+	}
+
+	token, err := decoder.Token()
+	if err != nil {
+		return header, collector.result, fmt.Errorf("读取题库文件失败: %v", err)
+	}
+	switch token {
+	case json.Delim('['):
+		if err := readArray(0); err != nil {
+			return header, collector.result, err
+		}
+	case json.Delim('{'):
+		// 文件头字段先收集起来，读完对象后统一解析
+		fields := map[string]json.RawMessage{}
+		hasItems := false
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return header, collector.result, fmt.Errorf("解析文件头失败: %v", err)
+			}
+			key, _ := keyToken.(string)
+			if key != "items" {
+				var value json.RawMessage
+				if err := decoder.Decode(&value); err != nil {
+					return header, collector.result, fmt.Errorf("解析文件头失败: %v", err)
+				}
+				fields[key] = value
+				continue
+			}
+			if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+				return header, collector.result, fmt.Errorf("items必须是数组")
+			}
+			hasItems = true
+			if err := readArray(0); err != nil {
+				return header, collector.result, err
+			}
+		}
+		if _, err := decoder.Token(); err != nil {
+			return header, collector.result, fmt.Errorf("解析文件头失败: %v", err)
+		}
+
+		raw, _ := json.Marshal(fields)
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return header, collector.result, fmt.Errorf("解析文件头失败: %v", err)
+		}
+		if header.Format != bankFileFormat {
+			return header, collector.result, fmt.Errorf("不是题库文件: format应为%s", bankFileFormat)
+		}
+		if header.Version > bankFileVersion {
+			return header, collector.result, fmt.Errorf("题库文件版本过高: %d", header.Version)
+		}
+
+		// JSON Lines：文件头之后每行一道题
+		if !hasItems {
+			for decoder.More() {
+				if err := readItem(1); err != nil {
+					return header, collector.result, err
+				}
+			}
+		}
+	default:
+		return header, collector.result, fmt.Errorf("不是题库文件: 应以{或[开头")
+	}
+
+	collector.result.Bank = &header
+	result, err := collector.finish()
+	return header, result, err
+}
+
+// normalizeImportedItem 整理从交换文件读入的题目：清除导入时产生的提示，
+// 没有记录答案字母的按选项补全
+func normalizeImportedItem(item AnswerItem) AnswerItem {
+	item.Warnings = nil
+	if item.Options == nil {
+		item.Options = []string{}
+	}
+	if item.Answer == nil {
+		item.Answer = []string{}
+	}
+	if len(item.AnswerKeys) == 0 {
+		item = resolveAnswerKeys(item)
+	}
+	return item
+}
+
+// findBank 按名称获取题库，name为空时返回当前激活的题库。
+// 题库创建后不会被原地修改，调用方可以在不持有锁的情况下读取
+func findBank(name string) (*QuestionBank, error) {
+	banks := currentBanks()
+
+	if name == "" {
+		name = banks.active
+	}
+	bank := banks.find(name)
+	if bank == nil {
+		return nil, fmt.Errorf("题库不存在: %s", name)
+	}
+	return bank, nil
+}
+
+// storeImportedBank 将导入的题目保存为题库，保留文件中记录的来源和创建时间。
+// 同名题库已存在时，replace为true则替换其题目，否则返回错误
+func storeImportedBank(name string, header BankFileHeader, answers []AnswerItem, replace bool) error {
+	name, err := normalizeBankName(name)
+	if err != nil {
+		return err
+	}
+
+	// 索引构建较慢，放在加锁之前完成
+	index := newSearchIndex(answers)
+
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	existing := banks.find(name)
+	if existing != nil && !replace {
+		return fmt.Errorf("题库已存在: %s", name)
+	}
+	if existing != nil {
+		answers, _ = assignQuestionIDs(answers, existing.Answers)
+	} else {
+		answers, _ = assignQuestionIDs(answers, nil)
+	}
+
+	now := time.Now()
+	imported := &QuestionBank{Name: name, Source: header.Source, Answers: answers, CreatedAt: header.CreatedAt, UpdatedAt: now, index: index}
+	if imported.CreatedAt.IsZero() {
+		imported.CreatedAt = now
+	}
+
+	next := &bankRegistry{active: banks.active}
+	for _, b := range banks.banks {
+		if b == existing {
+			b = imported
+		}
+		next.banks = append(next.banks, b)
+	}
+	if existing == nil {
+		next.banks = append(next.banks, imported)
+	}
+	if next.active == "" {
+		next.active = name
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	logBankVersion(existing, imported, bankChange{
+		action:  BankActionImport,
+		summary: fmt.Sprintf("从题库文件导入 %d 道题", len(answers)),
+	})
+	return nil
+}
+
+// ExportBankFile 将题库导出为JSON或JSON Lines文件（按扩展名决定），bankName为空时导出当前激活的题库
+func (e *ExamService) ExportBankFile(bankName string, filePath string) error {
+	bank, err := findBank(bankName)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomicFunc(filePath, func(w io.Writer) error {
+		return writeBankFile(w, bank, bankFormatFromPath(filePath))
+	})
+}
+
+// ParseBankFile 解析JSON或JSON Lines题库文件，不保存
+func (e *ExamService) ParseBankFile(filePath string) (ImportResult, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("无法打开文件: %v", err)
+	}
+	defer f.Close()
+
+	_, result, err := readBankFile(f, false)
+	return result, err
+}
+
+// ImportBankFile 导入JSON或JSON Lines题库文件并保存为题库。
+// bankName为空时使用文件中记录的名称，文件中也没有时使用文件名；replace为true时替换同名题库
+func (e *ExamService) ImportBankFile(filePath string, bankName string, replace bool) (ImportResult, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("无法打开文件: %v", err)
+	}
+	defer f.Close()
+
+	fallback := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	return importBank(f, bankName, fallback, replace, false)
+}
+
+// importBank 读取题库交换文件并保存，供文件导入和HTTP导入共用
+func importBank(r io.Reader, bankName string, fallbackName string, replace bool, strict bool) (ImportResult, error) {
+	header, result, err := readBankFile(r, strict)
+	if err != nil {
+		return result, err
+	}
+
+	name := bankName
+	if strings.TrimSpace(name) == "" {
+		name = header.Name
+	}
+	if strings.TrimSpace(name) == "" {
+		name = fallbackName
+	}
+	if err := storeImportedBank(name, header, result.Items, replace); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ImportBankResponse HTTP导入题库文件响应结构
+type ImportBankResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message,omitempty"`
+	Bank    *BankFileHeader `json:"bank,omitempty"`   // 文件头中的元数据
+	Report  *ImportReport   `json:"report,omitempty"` // 逐题导入报告
+	Banks   []BankInfo      `json:"banks,omitempty"`  // 导入后的题库列表
+}
+
+// handleImportBank 处理HTTP导入题库请求，请求体为题库文件内容，边读边解析。
+// 查询参数：bank为保存的题库名称（为空时使用文件中的名称），replace为true时替换同名题库，strict为true时任意一题有问题即整体失败
+func handleImportBank(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	replace, _ := strconv.ParseBool(query.Get("replace"))
+	strict, _ := strconv.ParseBool(query.Get("strict"))
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	result, err := importBank(r.Body, query.Get("bank"), defaultBankName, replace, strict)
+	if err != nil {
+		response := ImportBankResponse{
+			Success: false,
+			Message: "导入题库失败: " + err.Error(),
+			Bank:    result.Bank,
+			Report:  &result.Report,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := ImportBankResponse{
+		Success: true,
+		Message: result.Report.Summary(),
+		Bank:    result.Bank,
+		Report:  &result.Report,
+		Banks:   examService.ListBanks(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
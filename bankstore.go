@@ -0,0 +1,754 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// appDataDirName 应用在用户配置目录下使用的数据目录名
+const appDataDirName = "exam-assistant"
+
+// answerStoreFileName 题库持久化文件名
+const answerStoreFileName = "answers.json"
+
+// answerStoreVersion 题库文件格式版本
+// 1: 单一题库，只有answers字段
+// 2: 多个命名题库，记录当前激活的题库
+const answerStoreVersion = 2
+
+// defaultBankName 默认题库名称，旧版数据和未指定题库的导入都放在这里
+const defaultBankName = "默认题库"
+
+// QuestionBank 命名题库
+type QuestionBank struct {
+	Name      string       `json:"name"`
+	Source    string       `json:"source,omitempty"` // 题库来源，如导入文件中记录的来源
+	Answers   []AnswerItem `json:"answers"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+
+	index *searchIndex // 搜索用倒排索引，题目变化时重建
+}
+
+// BankInfo 题库概要信息
+type BankInfo struct {
+	Name      string    `json:"name"`
+	Source    string    `json:"source,omitempty"`
+	Count     int       `json:"count"`  // 题目数量
+	Active    bool      `json:"active"` // 是否为当前激活的题库
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// answerStoreFile 题库文件的磁盘格式
+type answerStoreFile struct {
+	Version   int             `json:"version"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+	Active    string          `json:"active,omitempty"`
+	Banks     []*QuestionBank `json:"banks,omitempty"`
+	Answers   []AnswerItem    `json:"answers,omitempty"` // 仅版本1使用
+}
+
+// bankRegistry 题库注册表，按创建顺序保存所有题库
+type bankRegistry struct {
+	active string
+	banks  []*QuestionBank
+}
+
+// find 按名称查找题库
+func (r *bankRegistry) find(name string) *QuestionBank {
+	for _, bank := range r.banks {
+		if bank.Name == name {
+			return bank
+		}
+	}
+	return nil
+}
+
+// activeBank 返回当前激活的题库，没有时返回nil
+func (r *bankRegistry) activeBank() *QuestionBank {
+	return r.find(r.active)
+}
+
+// 全局题库注册表。注册表和其中的题库发布后不再修改，读取时通过currentBanks取得快照，无需加锁；
+// 修改时持有bankMu，在副本上完成修改并保存后整体替换
+var (
+	bankState atomic.Pointer[bankRegistry]
+	bankMu    sync.Mutex
+)
+
+func init() {
+	bankState.Store(&bankRegistry{})
+}
+
+// currentBanks 返回当前题库注册表的快照
+func currentBanks() *bankRegistry {
+	return bankState.Load()
+}
+
+// appDataDir 返回应用数据目录，不存在时自动创建
+func appDataDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户配置目录失败: %v", err)
+	}
+
+	dir := filepath.Join(configDir, appDataDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建数据目录失败: %v", err)
+	}
+	return dir, nil
+}
+
+// answerStorePath 返回题库文件的完整路径
+func answerStorePath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, answerStoreFileName), nil
+}
+
+// writeFileAtomic 先写入同目录下的临时文件再重命名替换，
+// 写入过程中崩溃只会留下临时文件，原文件保持完整
+func writeFileAtomic(path string, data []byte) error {
+	return writeFileAtomicFunc(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomicFunc 与writeFileAtomic相同，但由write逐步写入内容，适合较大的文件
+func writeFileAtomicFunc(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	tmpPath := tmp.Name()
+
+	buffered := bufio.NewWriter(tmp)
+	if err := write(buffered); err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("同步临时文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("关闭临时文件失败: %v", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("替换文件失败: %v", err)
+	}
+	return nil
+}
+
+// saveBanks 将注册表写入磁盘，调用方需持有bankMu
+func saveBanks(r *bankRegistry) error {
+	path, err := answerStorePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(answerStoreFile{
+		Version:   answerStoreVersion,
+		UpdatedAt: time.Now(),
+		Active:    r.active,
+		Banks:     r.banks,
+	})
+	if err != nil {
+		return fmt.Errorf("编码题库失败: %v", err)
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// loadBanks 启动时从磁盘加载题库，文件不存在时视为空注册表
+func loadBanks() error {
+	path, err := answerStorePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取题库文件失败: %v", err)
+	}
+
+	var stored answerStoreFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return fmt.Errorf("解析题库文件失败: %v", err)
+	}
+	if stored.Version > answerStoreVersion {
+		return fmt.Errorf("题库文件版本过高: %d", stored.Version)
+	}
+
+	loaded := &bankRegistry{active: stored.Active, banks: stored.Banks}
+
+	// 版本1只有一个题库，迁移为默认题库
+	if stored.Version < 2 {
+		loaded.banks = []*QuestionBank{{
+			Name:      defaultBankName,
+			Answers:   stored.Answers,
+			CreatedAt: stored.UpdatedAt,
+			UpdatedAt: stored.UpdatedAt,
+		}}
+		loaded.active = defaultBankName
+	}
+
+	// 旧版数据中的题目没有ID，分配后立即保存，保证重启后ID不变
+	assigned := false
+	for _, bank := range loaded.banks {
+		var changed bool
+		bank.Answers, changed = assignQuestionIDs(bank.Answers, nil)
+		assigned = assigned || changed
+		bank.index = newSearchIndex(bank.Answers)
+	}
+
+	if loaded.activeBank() == nil && len(loaded.banks) > 0 {
+		loaded.active = loaded.banks[0].Name
+	}
+
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	if assigned {
+		if err := saveBanks(loaded); err != nil {
+			return fmt.Errorf("保存题库失败: %v", err)
+		}
+	}
+	bankState.Store(loaded)
+	return nil
+}
+
+// normalizeBankName 校验并清理题库名称
+func normalizeBankName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("题库名称不能为空")
+	}
+	return name, nil
+}
+
+// ListBanks 列出所有题库
+func (e *ExamService) ListBanks() []BankInfo {
+	banks := currentBanks()
+
+	infos := make([]BankInfo, 0, len(banks.banks))
+	for _, bank := range banks.banks {
+		infos = append(infos, BankInfo{
+			Name:      bank.Name,
+			Source:    bank.Source,
+			Count:     len(bank.Answers),
+			Active:    bank.Name == banks.active,
+			CreatedAt: bank.CreatedAt,
+			UpdatedAt: bank.UpdatedAt,
+		})
+	}
+	return infos
+}
+
+// CreateBank 新建空题库，注册表为空时新题库自动激活
+func (e *ExamService) CreateBank(name string) error {
+	name, err := normalizeBankName(name)
+	if err != nil {
+		return err
+	}
+
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	if banks.find(name) != nil {
+		return fmt.Errorf("题库已存在: %s", name)
+	}
+
+	now := time.Now()
+	next := &bankRegistry{
+		active: banks.active,
+		banks:  append(append([]*QuestionBank{}, banks.banks...), &QuestionBank{Name: name, Answers: []AnswerItem{}, CreatedAt: now, UpdatedAt: now, index: newSearchIndex(nil)}),
+	}
+	if next.active == "" {
+		next.active = name
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	return nil
+}
+
+// RenameBank 重命名题库
+func (e *ExamService) RenameBank(oldName string, newName string) error {
+	newName, err := normalizeBankName(newName)
+	if err != nil {
+		return err
+	}
+
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	bank := banks.find(oldName)
+	if bank == nil {
+		return fmt.Errorf("题库不存在: %s", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if banks.find(newName) != nil {
+		return fmt.Errorf("题库已存在: %s", newName)
+	}
+
+	renamed := *bank
+	renamed.Name = newName
+	renamed.UpdatedAt = time.Now()
+
+	next := &bankRegistry{active: banks.active}
+	for _, b := range banks.banks {
+		if b == bank {
+			b = &renamed
+		}
+		next.banks = append(next.banks, b)
+	}
+	if next.active == oldName {
+		next.active = newName
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	renameBankHistory(oldName, newName)
+	return nil
+}
+
+// DeleteBank 删除题库及其历史版本，删除激活的题库时自动激活剩余的第一个题库
+func (e *ExamService) DeleteBank(name string) error {
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	bank := banks.find(name)
+	if bank == nil {
+		return fmt.Errorf("题库不存在: %s", name)
+	}
+
+	next := &bankRegistry{active: banks.active}
+	for _, b := range banks.banks {
+		if b != bank {
+			next.banks = append(next.banks, b)
+		}
+	}
+	if next.active == name {
+		next.active = ""
+		if len(next.banks) > 0 {
+			next.active = next.banks[0].Name
+		}
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	removeBankHistory(name)
+	return nil
+}
+
+// SetActiveBank 切换当前激活的题库
+func (e *ExamService) SetActiveBank(name string) error {
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	if banks.find(name) == nil {
+		return fmt.Errorf("题库不存在: %s", name)
+	}
+
+	next := &bankRegistry{active: name, banks: banks.banks}
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	return nil
+}
+
+// SetBankAnswers 替换指定题库的全部题目，name为空时写入当前激活的题库，
+// 题库不存在时自动创建
+func (e *ExamService) SetBankAnswers(name string, answers []AnswerItem) error {
+	return storeBankAnswers(name, answers, nil, bankChange{
+		action:  BankActionImport,
+		summary: fmt.Sprintf("导入 %d 道题", len(answers)),
+	})
+}
+
+// storeBankAnswers 替换题库的全部题目并保存，name为空时写入当前激活的题库。
+// check不为空时在加锁后以题库当前状态（不存在时为nil）调用，返回错误则放弃写入，
+// 用于确认题库在计算新题目期间没有被其他操作修改。保存后将change记录为新的历史版本
+func storeBankAnswers(name string, answers []AnswerItem, check func(current *QuestionBank) error, change bankChange) error {
+	// 复制一份，调用方之后修改传入的题目不会影响已发布的题库
+	answers = cloneAnswers(answers)
+
+	// 索引构建较慢，放在加锁之前完成
+	index := newSearchIndex(answers)
+
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	if name == "" {
+		name = banks.active
+	}
+	if name == "" {
+		name = defaultBankName
+	}
+	current := banks.find(name)
+	if check != nil {
+		if err := check(current); err != nil {
+			return err
+		}
+	}
+	if current != nil {
+		answers, _ = assignQuestionIDs(answers, current.Answers)
+	} else {
+		answers, _ = assignQuestionIDs(answers, nil)
+	}
+
+	now := time.Now()
+	updated := &QuestionBank{Name: name, Answers: answers, CreatedAt: now, UpdatedAt: now, index: index}
+
+	next := &bankRegistry{active: banks.active}
+	found := false
+	for _, b := range banks.banks {
+		if b.Name == name {
+			updated.CreatedAt = b.CreatedAt
+			updated.Source = b.Source
+			b = updated
+			found = true
+		}
+		next.banks = append(next.banks, b)
+	}
+	if !found {
+		next.banks = append(next.banks, updated)
+	}
+	if next.active == "" {
+		next.active = name
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	logBankVersion(current, updated, change)
+	return nil
+}
+
+// updateBank 修改题库并保存，name为空时修改当前激活的题库。
+// apply在持有锁时调用，返回修改后的题库副本，不能修改传入的题库。
+// 保存后将change记录为新的历史版本，apply中可以补充change的说明
+func updateBank(name string, change *bankChange, apply func(bank *QuestionBank) (*QuestionBank, error)) error {
+	bankMu.Lock()
+	defer bankMu.Unlock()
+	banks := currentBanks()
+
+	if name == "" {
+		name = banks.active
+	}
+	bank := banks.find(name)
+	if bank == nil {
+		return fmt.Errorf("题库不存在: %s", name)
+	}
+
+	updated, err := apply(bank)
+	if err != nil {
+		return err
+	}
+	updated.UpdatedAt = time.Now()
+
+	next := &bankRegistry{active: banks.active}
+	for _, b := range banks.banks {
+		if b == bank {
+			b = updated
+		}
+		next.banks = append(next.banks, b)
+	}
+
+	if err := saveBanks(next); err != nil {
+		return fmt.Errorf("保存题库失败: %v", err)
+	}
+	bankState.Store(next)
+	logBankVersion(bank, updated, *change)
+	return nil
+}
+
+// newQuestionID 生成随机的题目ID
+func newQuestionID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// assignQuestionIDs 为没有ID或ID重复的题目分配ID，返回新的切片，不修改answers，
+// 第二个返回值表示是否分配了ID。previous为题库原有的题目，题目和选项相同的沿用原来的ID，
+// 这样重新导入同一份文件时ID保持不变
+func assignQuestionIDs(answers []AnswerItem, previous []AnswerItem) ([]AnswerItem, bool) {
+	used := map[string]bool{}
+	missing := false
+	for _, item := range answers {
+		if item.ID == "" || used[item.ID] {
+			missing = true
+			continue
+		}
+		used[item.ID] = true
+	}
+	if !missing {
+		return answers, false
+	}
+
+	e := &ExamService{}
+	reusable := map[string][]string{}
+	for _, item := range previous {
+		if item.ID != "" && !used[item.ID] {
+			key := e.fingerprint(item).key()
+			reusable[key] = append(reusable[key], item.ID)
+		}
+	}
+
+	assigned := make([]AnswerItem, len(answers))
+	owned := map[string]bool{}
+	for i, item := range answers {
+		if item.ID != "" && !owned[item.ID] {
+			owned[item.ID] = true
+			assigned[i] = item
+			continue
+		}
+
+		item.ID = ""
+		key := e.fingerprint(item).key()
+		for len(reusable[key]) > 0 && item.ID == "" {
+			if id := reusable[key][0]; !used[id] {
+				item.ID = id
+			}
+			reusable[key] = reusable[key][1:]
+		}
+		for item.ID == "" || used[item.ID] {
+			item.ID = newQuestionID()
+		}
+		used[item.ID], owned[item.ID] = true, true
+		assigned[i] = item
+	}
+	return assigned, true
+}
+
+// GetBankAnswers 获取指定题库的全部题目，name为空时返回当前激活的题库。
+// 返回的是副本，调用方可以自由修改
+func (e *ExamService) GetBankAnswers(name string) ([]AnswerItem, error) {
+	banks := currentBanks()
+
+	if name == "" {
+		if bank := banks.activeBank(); bank != nil {
+			return cloneAnswers(bank.Answers), nil
+		}
+		return []AnswerItem{}, nil
+	}
+
+	bank := banks.find(name)
+	if bank == nil {
+		return nil, fmt.Errorf("题库不存在: %s", name)
+	}
+	return cloneAnswers(bank.Answers), nil
+}
+
+// cloneAnswers 复制题目列表，包括每道题的选项、答案等切片
+func cloneAnswers(answers []AnswerItem) []AnswerItem {
+	if answers == nil {
+		return nil
+	}
+	cloned := make([]AnswerItem, len(answers))
+	for i, item := range answers {
+		item.Options = slices.Clone(item.Options)
+		item.Answer = slices.Clone(item.Answer)
+		item.AnswerKeys = slices.Clone(item.AnswerKeys)
+		item.Warnings = slices.Clone(item.Warnings)
+		item.Tags = slices.Clone(item.Tags)
+		cloned[i] = item
+	}
+	return cloned
+}
+
+// resolveSearchTargets 根据请求确定要搜索的题库：
+// allBanks为true时搜索全部题库，指定了names时搜索对应题库，否则搜索当前激活的题库
+func resolveSearchTargets(names []string, allBanks bool) ([]searchTarget, error) {
+	banks := currentBanks()
+
+	targets := []searchTarget{}
+	switch {
+	case allBanks:
+		for _, bank := range banks.banks {
+			targets = append(targets, searchTarget{bank: bank.Name, answers: bank.Answers, index: bank.index})
+		}
+	case len(names) > 0:
+		seen := map[string]bool{}
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			bank := banks.find(name)
+			if bank == nil {
+				return nil, fmt.Errorf("题库不存在: %s", name)
+			}
+			targets = append(targets, searchTarget{bank: bank.Name, answers: bank.Answers, index: bank.index})
+		}
+	default:
+		if bank := banks.activeBank(); bank != nil {
+			targets = append(targets, searchTarget{bank: bank.Name, answers: bank.Answers, index: bank.index})
+		}
+	}
+	return targets, nil
+}
+
+// BankRequest HTTP题库管理请求结构
+type BankRequest struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName,omitempty"` // 仅重命名时使用
+}
+
+// BankResponse HTTP题库管理响应结构，返回操作后的题库列表
+type BankResponse struct {
+	Success bool       `json:"success"`
+	Message string     `json:"message,omitempty"`
+	Banks   []BankInfo `json:"banks,omitempty"`
+}
+
+// handleListBanks 处理HTTP题库列表请求
+func handleListBanks(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response := BankResponse{
+		Success: true,
+		Banks:   examService.ListBanks(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleCreateBank 处理HTTP新建题库请求
+func handleCreateBank(w http.ResponseWriter, r *http.Request) {
+	serveBankMutation(w, r, "新建题库", func(e *ExamService, req BankRequest) error {
+		return e.CreateBank(req.Name)
+	})
+}
+
+// handleRenameBank 处理HTTP重命名题库请求
+func handleRenameBank(w http.ResponseWriter, r *http.Request) {
+	serveBankMutation(w, r, "重命名题库", func(e *ExamService, req BankRequest) error {
+		return e.RenameBank(req.Name, req.NewName)
+	})
+}
+
+// handleDeleteBank 处理HTTP删除题库请求
+func handleDeleteBank(w http.ResponseWriter, r *http.Request) {
+	serveBankMutation(w, r, "删除题库", func(e *ExamService, req BankRequest) error {
+		return e.DeleteBank(req.Name)
+	})
+}
+
+// handleSetActiveBank 处理HTTP切换激活题库请求
+func handleSetActiveBank(w http.ResponseWriter, r *http.Request) {
+	serveBankMutation(w, r, "切换题库", func(e *ExamService, req BankRequest) error {
+		return e.SetActiveBank(req.Name)
+	})
+}
+
+// serveBankMutation 题库管理类POST请求的公共处理流程
+func serveBankMutation(w http.ResponseWriter, r *http.Request, action string, apply func(e *ExamService, req BankRequest) error) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req BankRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	if err := apply(examService, req); err != nil {
+		response := BankResponse{
+			Success: false,
+			Message: action + "失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := BankResponse{
+		Success: true,
+		Message: action + "成功",
+		Banks:   examService.ListBanks(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
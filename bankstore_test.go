@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// useTempAppData 让题库和设置读写临时目录，并清空内存中的题库和设置
+func useTempAppData(t testing.TB) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("APPDATA", dir)
+	t.Setenv("HOME", dir)
+	bankState.Store(&bankRegistry{})
+	settingsMu.Lock()
+	settings = defaultSettings()
+	settingsMu.Unlock()
+}
+
+// raceBankItems 生成用于并发测试的题目
+func raceBankItems(round int) []AnswerItem {
+	items := []AnswerItem{}
+	for i := 0; i < 50; i++ {
+		items = append(items, AnswerItem{
+			Question: fmt.Sprintf("第%d轮 第%d题 光速是多少", round, i),
+			Options:  []string{"A. 很快", "B. 很慢"},
+			Answer:   []string{"A. 很快"},
+		})
+	}
+	return items
+}
+
+// TestBankStoreConcurrentSearchAndSet 搜索与修改题库同时进行，需要用go test -race运行
+func TestBankStoreConcurrentSearchAndSet(t *testing.T) {
+	useTempAppData(t)
+	e := &ExamService{}
+	if err := e.SetBankAnswers("并发", raceBankItems(0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.SetActiveBank("并发"); err != nil {
+		t.Fatal(err)
+	}
+
+	const rounds = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*rounds)
+	run := func(f func(round int) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 1; round <= rounds; round++ {
+				if err := f(round); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	run(func(int) error {
+		_, err := e.Search(SearchRequest{Query: "光速是多少", Banks: []string{"并发"}})
+		return err
+	})
+	run(func(int) error {
+		targets, err := resolveSearchTargets(nil, true)
+		for _, target := range targets {
+			// 读取快照中的题目，修改方不能原地改写
+			for _, item := range target.answers {
+				_ = item.Question
+			}
+		}
+		return err
+	})
+	run(func(round int) error {
+		return e.SetBankAnswers("并发", raceBankItems(round))
+	})
+	run(func(round int) error {
+		items, err := e.GetBankAnswers("并发")
+		if err != nil || len(items) == 0 {
+			return err
+		}
+		item := items[round%len(items)]
+		item.Explanation = fmt.Sprintf("第%d次修改", round)
+		// 题目可能已被并发的SetBankAnswers替换
+		if _, err := e.UpdateQuestion("并发", item.ID, item); err != nil && bankHasQuestion("并发", item.ID) {
+			return err
+		}
+		return nil
+	})
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	bank, err := findBank("并发")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bank.Answers) != 50 {
+		t.Errorf("题库有 %d 道题，期望 50 道", len(bank.Answers))
+	}
+}
+
+// bankHasQuestion 判断题库中当前是否有指定ID的题目
+func bankHasQuestion(name string, id string) bool {
+	bank, err := findBank(name)
+	return err == nil && bank.indexOf(id) >= 0
+}
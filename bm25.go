@@ -0,0 +1,135 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// 评分模式
+const (
+	ScoringModeHeuristic = "heuristic" // 编辑距离、关键词、字符重合度的综合评分（默认）
+	ScoringModeBM25      = "bm25"      // 基于字符n元组的BM25相关性评分
+)
+
+// BM25参数
+const (
+	bm25K1 = 1.2  // 词频饱和度
+	bm25B  = 0.75 // 文档长度归一化强度
+)
+
+// idf 计算词项的逆文档频率
+func (idx *searchIndex) idf(term string) float64 {
+	n := float64(idx.size)
+	df := float64(len(idx.postings[term]))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// bm25Term 单个词项的BM25得分
+func (idx *searchIndex) bm25Term(idf float64, tf float64, docLen float64) float64 {
+	norm := 1 - bm25B + bm25B*docLen/idx.avgDocLen
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+}
+
+// scoredDoc 题目下标及其得分
+type scoredDoc struct {
+	doc   int
+	score float64
+}
+
+// bm25Scores 计算查询与题库中各题目的BM25分数，返回分数最高的至多maxIndexCandidates个题目（按下标升序）。
+// 分数以查询文本自身作为文档时的得分进行归一化，使其与启发式评分一样落在0-1之间。
+// allowed不为nil时只对其中为true的题目评分
+func (idx *searchIndex) bm25Scores(queryTerms []string, allowed []bool) []scoredDoc {
+	scores := []scoredDoc{}
+	if len(queryTerms) == 0 || idx.size == 0 || idx.avgDocLen == 0 {
+		return scores
+	}
+
+	// 统计查询中各词项的出现次数
+	queryTF := map[string]int{}
+	for _, term := range queryTerms {
+		queryTF[term]++
+	}
+
+	ideal := 0.0
+	raw := make([]float64, idx.size)
+	for term, qtf := range queryTF {
+		idf := idx.idf(term)
+		ideal += idx.bm25Term(idf, float64(qtf), float64(len(queryTerms)))
+		for _, p := range idx.postings[term] {
+			raw[p.doc] += idx.bm25Term(idf, float64(p.tf), float64(idx.docLen[p.doc]))
+		}
+	}
+	if ideal == 0 {
+		return scores
+	}
+
+	docs := []int{}
+	for doc, score := range raw {
+		if score > 0 && (allowed == nil || allowed[doc]) {
+			docs = append(docs, doc)
+		}
+	}
+	if len(docs) > maxIndexCandidates {
+		sort.SliceStable(docs, func(a, b int) bool {
+			return raw[docs[a]] > raw[docs[b]]
+		})
+		docs = docs[:maxIndexCandidates]
+		sort.Ints(docs)
+	}
+
+	for _, doc := range docs {
+		score := raw[doc] / ideal
+		if score > 1.0 {
+			score = 1.0
+		}
+		scores = append(scores, scoredDoc{doc: doc, score: score})
+	}
+	return scores
+}
+
+// explainBM25 生成BM25评分明细，列出题目命中的查询词项
+func (idx *searchIndex) explainBM25(scored scoredDoc, queryTerms []string) *ScoreExplanation {
+	explanation := &ScoreExplanation{
+		Method:      MatchMethodBM25,
+		FieldWeight: 1,
+		RawScore:    scored.score,
+		Score:       scored.score,
+		Terms:       []string{},
+	}
+
+	seen := map[string]bool{}
+	for _, term := range queryTerms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		// 倒排表按题目下标升序，二分查找
+		list := idx.postings[term]
+		i := sort.Search(len(list), func(i int) bool { return int(list[i].doc) >= scored.doc })
+		if i < len(list) && int(list[i].doc) == scored.doc {
+			explanation.Terms = append(explanation.Terms, term)
+		}
+	}
+	return explanation
+}
+
+// scoreTargetBM25 使用BM25对题库评分，没有预建索引时临时建立
+func (e *ExamService) scoreTargetBM25(target searchTarget, normalizedQuery string, queryTerms []string, explain bool) []SearchResult {
+	idx := target.index
+	if idx == nil {
+		idx = newSearchIndex(target.answers)
+	}
+
+	results := []SearchResult{}
+	for _, scored := range idx.bm25Scores(queryTerms, target.allowed) {
+		result := e.highlightAnswer(target.answers[scored.doc], normalizedQuery)
+		result.Score = scored.score
+		result.Matched = "BM25: " + normalizedQuery
+		if explain {
+			result.Explanation = idx.explainBM25(scored, queryTerms)
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// answerColumns 题库文件必需的字段，CSV与Excel导入共用
+var answerColumns = []string{"类型", "题目", "选项", "答案"}
+
+// optionalAnswerColumns 题库文件可选的字段，缺失时对应内容为空
+var optionalAnswerColumns = []string{"解析", "分类", "难度", "章节", "标签", "来源"}
+
+// builtinHeaderAliases 内置的标题别名，字段名本身总是可以匹配
+var builtinHeaderAliases = map[string][]string{
+	"类型": {"题型", "题目类型", "试题类型", "Type", "Question Type"},
+	"题目": {"题干", "问题", "试题", "题目内容", "Question", "Stem"},
+	"选项": {"备选项", "选项内容", "Options", "Choices"},
+	"答案": {"正确答案", "参考答案", "标准答案", "Answer", "Correct Answer"},
+	"解析": {"答案解析", "试题解析", "解释", "Explanation", "Analysis"},
+	"分类": {"类别", "知识点", "Category"},
+	"难度": {"难易度", "难度等级", "Difficulty", "Level"},
+	"章节": {"所属章节", "章", "单元", "Chapter", "Unit"},
+	"标签": {"关键词", "Tags", "Tag", "Keywords"},
+	"来源": {"出处", "试题来源", "来源文档", "Source"},
+}
+
+// ColumnField 导入时可识别的字段
+type ColumnField struct {
+	Name     string   `json:"name"`     // 字段名，如"题目"
+	Required bool     `json:"required"` // 是否为必需字段
+	Aliases  []string `json:"aliases"`  // 可识别的标题别名，包括内置别名和用户别名
+}
+
+// ColumnProfile 针对某一来源题库保存的列映射方案
+type ColumnProfile struct {
+	Name    string            `json:"name"`    // 方案名称，通常为题库来源
+	Columns map[string]string `json:"columns"` // 字段名 -> 文件中的标题
+}
+
+// allAnswerColumns 返回全部字段，必需字段在前
+func allAnswerColumns() []string {
+	return append(append([]string{}, answerColumns...), optionalAnswerColumns...)
+}
+
+// isAnswerColumn 判断是否为可识别的字段名
+func isAnswerColumn(name string) bool {
+	for _, column := range allAnswerColumns() {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeHeader 标准化标题以便比较：忽略大小写、空白、BOM以及表示必填的星号和结尾冒号
+func normalizeHeader(header string) string {
+	header = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\uFEFF' || r == '*' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, header)
+	return strings.TrimRight(header, ":：")
+}
+
+// headerAliasLookup 建立标准化标题到字段名的查找表，包括字段名、内置别名和用户别名
+func headerAliasLookup(userAliases map[string][]string) map[string]string {
+	lookup := map[string]string{}
+	for _, column := range allAnswerColumns() {
+		lookup[normalizeHeader(column)] = column
+		for _, alias := range builtinHeaderAliases[column] {
+			lookup[normalizeHeader(alias)] = column
+		}
+	}
+	for _, column := range allAnswerColumns() {
+		for _, alias := range userAliases[column] {
+			lookup[normalizeHeader(alias)] = column
+		}
+	}
+	return lookup
+}
+
+// mapAnswerHeaders 根据标题行定位各字段所在的列，未找到的字段为-1。
+// 指定了列映射方案时优先按方案对应，其余字段按字段名和别名识别；
+// 缺少必需字段时返回HeaderError，列出缺失字段、未识别的标题和完整的标题行
+func mapAnswerHeaders(headers []string, profileName string) (map[string]int, error) {
+	current := currentSettings()
+
+	var profile *ColumnProfile
+	if profileName != "" {
+		for i := range current.ColumnProfiles {
+			if current.ColumnProfiles[i].Name == profileName {
+				profile = &current.ColumnProfiles[i]
+				break
+			}
+		}
+		if profile == nil {
+			return nil, fmt.Errorf("未找到列映射方案: %s", profileName)
+		}
+	}
+
+	columns := map[string]int{}
+	for _, key := range allAnswerColumns() {
+		columns[key] = -1
+	}
+	used := make([]bool, len(headers))
+
+	// 按映射方案对应
+	if profile != nil {
+		for _, key := range allAnswerColumns() {
+			want := normalizeHeader(profile.Columns[key])
+			if want == "" {
+				continue
+			}
+			for i, h := range headers {
+				if !used[i] && normalizeHeader(h) == want {
+					columns[key] = i
+					used[i] = true
+					break
+				}
+			}
+		}
+	}
+
+	// 按字段名和别名识别其余的列
+	lookup := headerAliasLookup(current.HeaderAliases)
+	for i, h := range headers {
+		if used[i] {
+			continue
+		}
+		if key, ok := lookup[normalizeHeader(h)]; ok && columns[key] == -1 {
+			columns[key] = i
+			used[i] = true
+		}
+	}
+
+	// 检查缺失字段
+	headerErr := HeaderError{Missing: []string{}, Extra: []string{}, Headers: headers}
+	for _, key := range answerColumns {
+		if columns[key] == -1 {
+			headerErr.Missing = append(headerErr.Missing, key)
+		}
+	}
+	if len(headerErr.Missing) > 0 {
+		for i, h := range headers {
+			if !used[i] && strings.TrimSpace(h) != "" {
+				headerErr.Extra = append(headerErr.Extra, h)
+			}
+		}
+		return nil, headerErr
+	}
+
+	return columns, nil
+}
+
+// headerErrorOf 取出错误中的标题行错误，其他错误返回nil
+func headerErrorOf(err error) *HeaderError {
+	var headerErr HeaderError
+	if errors.As(err, &headerErr) {
+		return &headerErr
+	}
+	return nil
+}
+
+// GetColumnFields 获取导入时可识别的字段及其别名
+func (e *ExamService) GetColumnFields() []ColumnField {
+	userAliases := currentSettings().HeaderAliases
+	required := map[string]bool{}
+	for _, key := range answerColumns {
+		required[key] = true
+	}
+
+	fields := []ColumnField{}
+	for _, key := range allAnswerColumns() {
+		aliases := append([]string{}, builtinHeaderAliases[key]...)
+		aliases = append(aliases, userAliases[key]...)
+		fields = append(fields, ColumnField{Name: key, Required: required[key], Aliases: aliases})
+	}
+	return fields
+}
+
+// GetHeaderAliases 获取用户自定义的标题别名（字段名 -> 别名列表）
+func (e *ExamService) GetHeaderAliases() map[string][]string {
+	aliases := currentSettings().HeaderAliases
+	if aliases == nil {
+		return map[string][]string{}
+	}
+	return aliases
+}
+
+// SetHeaderAliases 设置用户自定义的标题别名并保存，替换之前的全部用户别名
+func (e *ExamService) SetHeaderAliases(aliases map[string][]string) error {
+	for key := range aliases {
+		if !isAnswerColumn(key) {
+			return fmt.Errorf("未知字段: %s", key)
+		}
+	}
+
+	cleaned := map[string][]string{}
+	owner := map[string]string{}
+	for _, key := range allAnswerColumns() {
+		for _, alias := range aliases[key] {
+			alias = strings.TrimSpace(alias)
+			if normalizeHeader(alias) == "" {
+				continue
+			}
+			if other, ok := owner[normalizeHeader(alias)]; ok && other != key {
+				return fmt.Errorf("别名%s不能同时对应%s和%s", alias, other, key)
+			}
+			owner[normalizeHeader(alias)] = key
+			cleaned[key] = append(cleaned[key], alias)
+		}
+	}
+
+	return updateSettings(func(s *AppSettings) error {
+		s.HeaderAliases = cleaned
+		return nil
+	})
+}
+
+// ListColumnProfiles 列出已保存的列映射方案
+func (e *ExamService) ListColumnProfiles() []ColumnProfile {
+	profiles := currentSettings().ColumnProfiles
+	if profiles == nil {
+		return []ColumnProfile{}
+	}
+	return profiles
+}
+
+// SaveColumnProfile 保存列映射方案，同名方案将被替换
+func (e *ExamService) SaveColumnProfile(profile ColumnProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("方案名称不能为空")
+	}
+
+	for key := range profile.Columns {
+		if !isAnswerColumn(key) {
+			return fmt.Errorf("未知字段: %s", key)
+		}
+	}
+
+	columns := map[string]string{}
+	headerOwner := map[string]string{}
+	for _, key := range allAnswerColumns() {
+		header := strings.TrimSpace(profile.Columns[key])
+		if header == "" {
+			continue
+		}
+		if other, ok := headerOwner[normalizeHeader(header)]; ok {
+			return fmt.Errorf("标题%s不能同时对应%s和%s", header, other, key)
+		}
+		headerOwner[normalizeHeader(header)] = key
+		columns[key] = header
+	}
+	profile.Columns = columns
+
+	return updateSettings(func(s *AppSettings) error {
+		// 复制后修改，避免影响正在读取旧设置的调用方
+		profiles := make([]ColumnProfile, 0, len(s.ColumnProfiles)+1)
+		replaced := false
+		for _, existing := range s.ColumnProfiles {
+			if existing.Name == profile.Name {
+				existing = profile
+				replaced = true
+			}
+			profiles = append(profiles, existing)
+		}
+		if !replaced {
+			profiles = append(profiles, profile)
+		}
+		s.ColumnProfiles = profiles
+		return nil
+	})
+}
+
+// DeleteColumnProfile 删除列映射方案
+func (e *ExamService) DeleteColumnProfile(name string) error {
+	return updateSettings(func(s *AppSettings) error {
+		profiles := make([]ColumnProfile, 0, len(s.ColumnProfiles))
+		for _, existing := range s.ColumnProfiles {
+			if existing.Name != name {
+				profiles = append(profiles, existing)
+			}
+		}
+		if len(profiles) == len(s.ColumnProfiles) {
+			return fmt.Errorf("列映射方案不存在: %s", name)
+		}
+		s.ColumnProfiles = profiles
+		return nil
+	})
+}
+
+// ColumnMappingRequest HTTP列映射设置请求结构
+type ColumnMappingRequest struct {
+	Aliases map[string][]string `json:"aliases,omitempty"` // 设置别名时使用
+	Profile ColumnProfile       `json:"profile"`           // 保存方案时使用
+	Name    string              `json:"name,omitempty"`    // 删除方案时使用
+}
+
+// ColumnMappingResponse HTTP列映射设置响应结构
+type ColumnMappingResponse struct {
+	Success  bool                `json:"success"`
+	Message  string              `json:"message,omitempty"`
+	Fields   []ColumnField       `json:"fields,omitempty"`
+	Aliases  map[string][]string `json:"aliases,omitempty"`
+	Profiles []ColumnProfile     `json:"profiles"`
+}
+
+// columnMappingResponse 汇总当前的字段、别名和映射方案
+func columnMappingResponse(examService *ExamService, message string) ColumnMappingResponse {
+	return ColumnMappingResponse{
+		Success:  true,
+		Message:  message,
+		Fields:   examService.GetColumnFields(),
+		Aliases:  examService.GetHeaderAliases(),
+		Profiles: examService.ListColumnProfiles(),
+	}
+}
+
+// handleGetColumnMapping 处理HTTP获取列映射设置请求
+func handleGetColumnMapping(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(columnMappingResponse(examService, ""))
+}
+
+// handleSetHeaderAliases 处理HTTP设置标题别名请求
+func handleSetHeaderAliases(w http.ResponseWriter, r *http.Request) {
+	serveColumnMappingMutation(w, r, "设置标题别名", func(e *ExamService, req ColumnMappingRequest) error {
+		return e.SetHeaderAliases(req.Aliases)
+	})
+}
+
+// handleSaveColumnProfile 处理HTTP保存列映射方案请求
+func handleSaveColumnProfile(w http.ResponseWriter, r *http.Request) {
+	serveColumnMappingMutation(w, r, "保存列映射方案", func(e *ExamService, req ColumnMappingRequest) error {
+		return e.SaveColumnProfile(req.Profile)
+	})
+}
+
+// handleDeleteColumnProfile 处理HTTP删除列映射方案请求
+func handleDeleteColumnProfile(w http.ResponseWriter, r *http.Request) {
+	serveColumnMappingMutation(w, r, "删除列映射方案", func(e *ExamService, req ColumnMappingRequest) error {
+		return e.DeleteColumnProfile(req.Name)
+	})
+}
+
+// serveColumnMappingMutation 列映射设置修改类请求的通用处理流程
+func serveColumnMappingMutation(w http.ResponseWriter, r *http.Request, action string, apply func(e *ExamService, req ColumnMappingRequest) error) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ColumnMappingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	if err := apply(examService, req); err != nil {
+		response := ColumnMappingResponse{
+			Success:  false,
+			Message:  action + "失败: " + err.Error(),
+			Profiles: examService.ListColumnProfiles(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(columnMappingResponse(examService, action+"成功"))
+}
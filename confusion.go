@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// confusionFileName 用户自定义形近字表文件名，保存在应用数据目录
+const confusionFileName = "ocr_confusions.txt"
+
+// defaultConfusionCost 形近字互相替换的默认代价，真正的字符不匹配代价为1
+const defaultConfusionCost = 0.3
+
+// ConfusionGroup 一组OCR容易互相识别错误的字符
+type ConfusionGroup struct {
+	Chars   string  `json:"chars"`   // 组内字符，如"己已巳"
+	Cost    float64 `json:"cost"`    // 组内字符互相替换的代价（0-1）
+	BuiltIn bool    `json:"builtIn"` // 是否为内置分组
+}
+
+// builtinConfusionGroups 内置的形近字分组，比较前文本已转为小写
+var builtinConfusionGroups = []ConfusionGroup{
+	{Chars: "己已巳", Cost: defaultConfusionCost},
+	{Chars: "未末", Cost: defaultConfusionCost},
+	{Chars: "戊戌戍", Cost: defaultConfusionCost},
+	{Chars: "日曰", Cost: defaultConfusionCost},
+	{Chars: "土士", Cost: defaultConfusionCost},
+	{Chars: "人入八", Cost: defaultConfusionCost},
+	{Chars: "千干于", Cost: defaultConfusionCost},
+	{Chars: "大太犬", Cost: defaultConfusionCost},
+	{Chars: "刀力", Cost: defaultConfusionCost},
+	{Chars: "贝见", Cost: defaultConfusionCost},
+	{Chars: "0oο〇", Cost: defaultConfusionCost},
+	{Chars: "l1i", Cost: defaultConfusionCost},
+	{Chars: "5s", Cost: defaultConfusionCost},
+	{Chars: "-一", Cost: defaultConfusionCost},
+}
+
+// confusionTable 形近字查找表，创建后只读
+type confusionTable struct {
+	groups   []ConfusionGroup
+	cost     map[rune]map[rune]float64
+	partners map[rune][]confusionPartner // 按代价从低到高排列，保证计算结果稳定
+}
+
+// confusionPartner 某个字符的一个形近字
+type confusionPartner struct {
+	char rune
+	cost float64
+}
+
+// 当前生效的形近字表，整体替换以避免搜索过程中加锁
+var confusions atomic.Pointer[confusionTable]
+
+func init() {
+	confusions.Store(newConfusionTable(nil))
+}
+
+// newConfusionTable 由内置分组和用户分组建立查找表，同一对字符取较低的代价
+func newConfusionTable(userGroups []ConfusionGroup) *confusionTable {
+	table := &confusionTable{cost: map[rune]map[rune]float64{}}
+
+	for _, group := range builtinConfusionGroups {
+		group.BuiltIn = true
+		table.add(group)
+	}
+	for _, group := range userGroups {
+		group.BuiltIn = false
+		table.add(group)
+	}
+
+	table.partners = make(map[rune][]confusionPartner, len(table.cost))
+	for char, others := range table.cost {
+		list := make([]confusionPartner, 0, len(others))
+		for other, cost := range others {
+			list = append(list, confusionPartner{char: other, cost: cost})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].cost != list[j].cost {
+				return list[i].cost < list[j].cost
+			}
+			return list[i].char < list[j].char
+		})
+		table.partners[char] = list
+	}
+
+	return table
+}
+
+// add 加入一组形近字
+func (t *confusionTable) add(group ConfusionGroup) {
+	t.groups = append(t.groups, group)
+	chars := []rune(group.Chars)
+	for i := range chars {
+		for j := i + 1; j < len(chars); j++ {
+			t.addPair(chars[i], chars[j], group.Cost)
+		}
+	}
+}
+
+// addPair 记录两个字符的替换代价（对称）
+func (t *confusionTable) addPair(a, b rune, cost float64) {
+	if a == b {
+		return
+	}
+	for _, pair := range [][2]rune{{a, b}, {b, a}} {
+		if t.cost[pair[0]] == nil {
+			t.cost[pair[0]] = map[rune]float64{}
+		}
+		if old, ok := t.cost[pair[0]][pair[1]]; !ok || cost < old {
+			t.cost[pair[0]][pair[1]] = cost
+		}
+	}
+}
+
+// substitutionCost 返回用b替换a的代价：相同为0，形近字为分组代价，否则为1
+func (t *confusionTable) substitutionCost(a, b rune) float64 {
+	if a == b {
+		return 0
+	}
+	if cost, ok := t.cost[a][b]; ok {
+		return cost
+	}
+	return 1
+}
+
+// parseConfusionGroups 解析形近字文件。
+// 每行一组形近字，可在空白后跟替换代价，如"己已巳 0.2"；以#开头的行为注释
+func parseConfusionGroups(r io.Reader) ([]ConfusionGroup, error) {
+	groups := []ConfusionGroup{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		group := ConfusionGroup{Chars: fields[0], Cost: defaultConfusionCost}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("第%d行格式错误: 应为\"字符组 [代价]\"", line)
+		}
+		if len(fields) == 2 {
+			cost, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || cost < 0 || cost > 1 {
+				return nil, fmt.Errorf("第%d行代价无效: %s，应为0到1之间的数字", line, fields[1])
+			}
+			group.Cost = cost
+		}
+		if len([]rune(group.Chars)) < 2 {
+			return nil, fmt.Errorf("第%d行至少需要两个字符: %s", line, fields[0])
+		}
+		// 与搜索一致，折叠后按小写比较。折叠后只剩一个字符的（如繁简对照"後后"）已由标准化处理，不再需要
+		group.Chars = foldConfusionChars(group.Chars)
+		if len([]rune(group.Chars)) < 2 {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取形近字文件失败: %v", err)
+	}
+	return groups, nil
+}
+
+// foldConfusionChars 按搜索时的标准化方式折叠形近字：全角转半角、繁体转简体、转为小写，
+// 折叠为同一字符的只保留一个
+func foldConfusionChars(chars string) string {
+	folded := []rune{}
+	for _, char := range chars {
+		char = unicode.ToLower(foldChar(char))
+		if !slices.Contains(folded, char) {
+			folded = append(folded, char)
+		}
+	}
+	return string(folded)
+}
+
+// confusionFilePath 返回用户形近字文件的完整路径
+func confusionFilePath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, confusionFileName), nil
+}
+
+// loadConfusions 启动时加载用户形近字文件，文件不存在时只使用内置分组
+func loadConfusions() error {
+	path, err := confusionFilePath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("打开形近字文件失败: %v", err)
+	}
+	defer f.Close()
+
+	groups, err := parseConfusionGroups(f)
+	if err != nil {
+		return err
+	}
+	confusions.Store(newConfusionTable(groups))
+	return nil
+}
+
+// GetConfusionGroups 获取当前生效的形近字分组
+func (e *ExamService) GetConfusionGroups() []ConfusionGroup {
+	return confusions.Load().groups
+}
+
+// ImportConfusionFile 导入用户形近字文件，替换之前导入的用户分组，返回导入的分组数量
+func (e *ExamService) ImportConfusionFile(filePath string) (int, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("无法打开文件: %v", err)
+	}
+
+	groups, err := parseConfusionGroups(bytes.NewReader(data))
+	if err != nil {
+		return 0, err
+	}
+
+	path, err := confusionFilePath()
+	if err != nil {
+		return 0, err
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return 0, err
+	}
+
+	confusions.Store(newConfusionTable(groups))
+	return len(groups), nil
+}
+
+// ConfusionResponse HTTP形近字表响应结构
+type ConfusionResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message,omitempty"`
+	Groups  []ConfusionGroup `json:"groups,omitempty"`
+}
+
+// ImportConfusionRequest HTTP导入形近字文件请求结构
+type ImportConfusionRequest struct {
+	FilePath string `json:"filePath"`
+}
+
+// handleGetConfusions 处理HTTP获取形近字表请求
+func handleGetConfusions(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response := ConfusionResponse{
+		Success: true,
+		Groups:  examService.GetConfusionGroups(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleImportConfusions 处理HTTP导入形近字文件请求
+func handleImportConfusions(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ImportConfusionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	count, err := examService.ImportConfusionFile(req.FilePath)
+	if err != nil {
+		response := ConfusionResponse{
+			Success: false,
+			Message: "导入形近字文件失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := ConfusionResponse{
+		Success: true,
+		Message: fmt.Sprintf("成功导入 %d 组形近字", count),
+		Groups:  examService.GetConfusionGroups(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseConfusionGroupsFoldsChars(t *testing.T) {
+	groups, err := parseConfusionGroups(strings.NewReader("# 用户形近字\n問間 0.2\nＯ０\n後后\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Chars != "问间" || groups[1].Chars != "o0" {
+		t.Fatalf("分组 = %+v", groups)
+	}
+
+	table := newConfusionTable(groups)
+	if cost := table.substitutionCost('问', '间'); cost != 0.2 {
+		t.Errorf("问/间 代价 = %v", cost)
+	}
+
+	// 繁体写法的查询与简体题目比较时使用用户分组的代价
+	e := &ExamService{}
+	confusions.Store(table)
+	defer confusions.Store(newConfusionTable(nil))
+	query := strings.ToLower(e.normalizeText("時間"))
+	if got := e.calculateEditDistance([]rune(query), []rune("时问")); got != 0.2 {
+		t.Errorf("编辑距离 = %v，期望 0.2", got)
+	}
+}
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// docxLevel 自动编号中一级的格式，如numFmt为decimal、lvlText为"%1."时显示为"1."
+type docxLevel struct {
+	Level int `xml:"ilvl,attr"`
+	Start struct {
+		Val int `xml:"val,attr"`
+	} `xml:"start"`
+	NumFmt struct {
+		Val string `xml:"val,attr"`
+	} `xml:"numFmt"`
+	LvlText struct {
+		Val string `xml:"val,attr"`
+	} `xml:"lvlText"`
+}
+
+// docxNumberingXML word/numbering.xml中与编号显示有关的部分
+type docxNumberingXML struct {
+	AbstractNums []struct {
+		ID     string      `xml:"abstractNumId,attr"`
+		Levels []docxLevel `xml:"lvl"`
+	} `xml:"abstractNum"`
+	Nums []struct {
+		ID         string `xml:"numId,attr"`
+		AbstractID struct {
+			Val string `xml:"val,attr"`
+		} `xml:"abstractNumId"`
+	} `xml:"num"`
+}
+
+// docxNumbering 还原Word自动编号的文字。自动编号不在段落文字中，
+// 题号和选项字母常常由此生成，需要按编号格式补回到段落开头
+type docxNumbering struct {
+	levels   map[string]map[int]docxLevel // numId -> 级别 -> 格式
+	counters map[string][]int             // numId -> 各级当前序号
+}
+
+// newDocxNumbering 解析numbering.xml，文档没有自动编号时data为空
+func newDocxNumbering(data []byte) *docxNumbering {
+	n := &docxNumbering{levels: map[string]map[int]docxLevel{}, counters: map[string][]int{}}
+	if len(data) == 0 {
+		return n
+	}
+	var numbering docxNumberingXML
+	if err := xml.Unmarshal(data, &numbering); err != nil {
+		return n
+	}
+
+	abstract := map[string]map[int]docxLevel{}
+	for _, a := range numbering.AbstractNums {
+		levels := map[int]docxLevel{}
+		for _, level := range a.Levels {
+			levels[level.Level] = level
+		}
+		abstract[a.ID] = levels
+	}
+	for _, num := range numbering.Nums {
+		if levels, ok := abstract[num.AbstractID.Val]; ok {
+			n.levels[num.ID] = levels
+		}
+	}
+	return n
+}
+
+// label 推进编号计数并返回该段落的编号文字，如"1."、"A."，项目符号和无法识别的编号返回空字符串
+func (n *docxNumbering) label(numID string, level int) string {
+	levels, ok := n.levels[numID]
+	if !ok || level < 0 || level > 8 {
+		return ""
+	}
+	counters, ok := n.counters[numID]
+	if !ok {
+		counters = make([]int, 9)
+		n.counters[numID] = counters
+	}
+	// 本级序号加一，下级编号重新开始
+	if counters[level] == 0 {
+		counters[level] = max(levels[level].Start.Val, 1)
+	} else {
+		counters[level]++
+	}
+	for i := level + 1; i < len(counters); i++ {
+		counters[i] = 0
+	}
+
+	format := levels[level].NumFmt.Val
+	if format == "bullet" || format == "none" {
+		return ""
+	}
+	text := levels[level].LvlText.Val
+	for i := 0; i <= level; i++ {
+		text = strings.ReplaceAll(text, "%"+strconv.Itoa(i+1), formatDocxNumber(max(counters[i], 1), levels[i].NumFmt.Val))
+	}
+	return text
+}
+
+// formatDocxNumber 按Word编号格式显示序号
+func formatDocxNumber(value int, format string) string {
+	switch format {
+	case "upperLetter", "lowerLetter":
+		// Word的字母编号在Z之后为AA、BB……
+		letter := strings.Repeat(string(rune('A'+(value-1)%26)), (value-1)/26+1)
+		if format == "lowerLetter" {
+			return strings.ToLower(letter)
+		}
+		return letter
+	case "chineseCounting", "chineseCountingThousand", "ideographTraditional", "taiwaneseCountingThousand":
+		return chineseNumber(value)
+	case "decimalEnclosedCircle", "decimalEnclosedCircleChinese":
+		if value >= 1 && value <= 20 {
+			return string(rune('①' + value - 1))
+		}
+	}
+	return strconv.Itoa(value)
+}
+
+// chineseNumber 将1-99转换为中文数字，如12为"十二"，超出范围时使用阿拉伯数字
+func chineseNumber(value int) string {
+	digits := []string{"", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	switch {
+	case value >= 1 && value <= 9:
+		return digits[value]
+	case value >= 10 && value <= 19:
+		return "十" + digits[value%10]
+	case value >= 20 && value <= 99:
+		return digits[value/10] + "十" + digits[value%10]
+	default:
+		return strconv.Itoa(value)
+	}
+}
+
+// readDocxParagraphs 读取.docx文档正文的全部段落（含表格中的段落），自动编号补回到段落开头。
+// 段落中的换行符保留为"\n"，制表符保留为"\t"
+func readDocxParagraphs(filePath string) ([]string, error) {
+	if strings.ToLower(filepath.Ext(filePath)) == ".doc" {
+		return nil, fmt.Errorf("暂不支持旧版.doc格式，请在Word中另存为.docx后重新导入")
+	}
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开Word文档: %v", err)
+	}
+	defer archive.Close()
+
+	readPart := func(name string) ([]byte, error) {
+		for _, f := range archive.File {
+			if f.Name != name {
+				continue
+			}
+			r, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return io.ReadAll(r)
+		}
+		return nil, nil
+	}
+
+	document, err := readPart("word/document.xml")
+	if err != nil || document == nil {
+		return nil, fmt.Errorf("不是有效的Word文档: 缺少word/document.xml")
+	}
+	numberingData, _ := readPart("word/numbering.xml")
+	numbering := newDocxNumbering(numberingData)
+
+	paragraphs := []string{}
+	decoder := xml.NewDecoder(bytes.NewReader(document))
+	var text strings.Builder
+	depth := 0 // 文本框中的段落嵌套在外层段落内，其文字归入外层段落
+	inRun, inText := false, false
+	numID, level := "", 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析Word文档失败: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				if depth == 0 {
+					text.Reset()
+					numID, level = "", 0
+				}
+				depth++
+			case "r":
+				inRun = true
+			case "t":
+				inText = true
+			case "tab":
+				// 段落属性中的tab为制表位定义，只有文字中的tab才是制表符
+				if inRun {
+					text.WriteString("\t")
+				}
+			case "br", "cr":
+				if inRun {
+					text.WriteString("\n")
+				}
+			case "numId":
+				if depth == 1 {
+					numID = docxAttr(t, "val")
+				}
+			case "ilvl":
+				if depth == 1 {
+					level, _ = strconv.Atoi(docxAttr(t, "val"))
+				}
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			case "p":
+				depth--
+				if depth > 0 {
+					text.WriteString("\n")
+					continue
+				}
+				paragraph := text.String()
+				if label := numbering.label(numID, level); label != "" {
+					paragraph = label + " " + paragraph
+				}
+				paragraphs = append(paragraphs, paragraph)
+			}
+		}
+	}
+	return paragraphs, nil
+}
+
+// docxAttr 读取元素的属性值，忽略命名空间前缀
+func docxAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// ParseDocxFile 按请求中的预设、切分规则和严格模式解析Word .docx题库，与PreviewDocxFile使用相同的请求，
+// 预览确认后可以原样导入。有问题的题目会被跳过，返回结果中的导入报告列出跳过的题目
+func (e *ExamService) ParseDocxFile(req ParseDocxRequest) (ImportResult, error) {
+	result, _, err := e.parseDocxFile(req)
+	return result, err
+}
+
+// PreviewDocxFile 预览Word .docx题库的切分结果，返回前PreviewCount道题的原文和解析结果，不导入
+func (e *ExamService) PreviewDocxFile(req ParseDocxRequest) ([]ParsedQuestion, error) {
+	count := req.PreviewCount
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	_, parsed, err := e.parseDocxFile(req)
+	if len(parsed) > count {
+		parsed = parsed[:count]
+	}
+	return parsed, err
+}
+
+// parseDocxFile 读取.docx文档段落并按切分规则解析，返回导入结果和全部切分结果。
+// 报告中的行号为段落序号
+func (e *ExamService) parseDocxFile(req ParseDocxRequest) (ImportResult, []ParsedQuestion, error) {
+	matchers, err := resolveSegmentPatterns(req.Preset, req.Patterns)
+	if err != nil {
+		return newImportCollector(req.Strict).result, nil, err
+	}
+	paragraphs, err := readDocxParagraphs(req.FilePath)
+	if err != nil {
+		return newImportCollector(req.Strict).result, nil, err
+	}
+
+	parsed := segmentQuestions(paragraphs, matchers)
+	if len(parsed) == 0 {
+		return newImportCollector(req.Strict).result, parsed, fmt.Errorf("没有识别出题目，请检查题号规则")
+	}
+	result, err := collectParsedQuestions(parsed, req.Strict)
+	result.setSource(req.FilePath)
+	return result, parsed, err
+}
+
+// ParseDocxRequest Word文档解析请求结构，HTTP接口和桌面端共用
+type ParseDocxRequest struct {
+	FilePath     string          `json:"filePath"`
+	Preset       string          `json:"preset,omitempty"`       // 切分规则预设，为空时使用常见格式
+	Patterns     SegmentPatterns `json:"patterns"`               // 切分规则，不为空的字段覆盖预设中的规则
+	Strict       bool            `json:"strict,omitempty"`       // 严格模式：任意一题有问题即整体失败，默认跳过有问题的题目
+	PreviewCount int             `json:"previewCount,omitempty"` // HTTP接口中大于0时为预览模式，只返回前N道题的原文和解析结果；PreviewDocxFile中为0时预览默认数量
+}
+
+// ParseDocxResponse HTTP Word文档解析响应结构
+type ParseDocxResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message,omitempty"`
+	Results []AnswerItem     `json:"results,omitempty"` // 全部题目，预览模式下不返回
+	Preview []ParsedQuestion `json:"preview,omitempty"` // 预览模式下前N道题的切分结果
+	Report  *ImportReport    `json:"report,omitempty"`  // 整个文档的导入报告
+}
+
+// handleParseDocx 处理HTTP Word文档解析请求
+func handleParseDocx(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ParseDocxRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	result, parsed, err := examService.parseDocxFile(req)
+	if req.PreviewCount > 0 && len(parsed) > req.PreviewCount {
+		parsed = parsed[:req.PreviewCount]
+	}
+	if err != nil {
+		response := ParseDocxResponse{
+			Success: false,
+			Message: "Word文档解析失败: " + err.Error(),
+			Report:  &result.Report,
+		}
+		// 预览模式下即使有问题的题目也返回切分结果，便于调整规则
+		if req.PreviewCount > 0 {
+			response.Preview = parsed
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := ParseDocxResponse{
+		Success: true,
+		Message: result.Report.Summary(),
+		Report:  &result.Report,
+	}
+	if req.PreviewCount > 0 {
+		response.Preview = parsed
+	} else {
+		response.Results = result.Items
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
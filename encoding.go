@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// 文件编码名称
+const (
+	EncodingAuto    = "auto"     // 自动检测
+	EncodingUTF8    = "utf-8"    // UTF-8，带或不带BOM
+	EncodingGBK     = "gbk"      // GBK，兼容GB2312
+	EncodingGB18030 = "gb18030"  // GB18030，兼容GBK
+	EncodingUTF16LE = "utf-16le" // UTF-16小端序，Excel导出"Unicode文本"时使用
+	EncodingUTF16BE = "utf-16be" // UTF-16大端序
+	EncodingBig5    = "big5"     // Big5繁体中文
+)
+
+// 自动检测时依次尝试的多字节编码。GB18030几乎可以解码任意字节，需要按解码结果的常用字比例挑选
+var autoEncodingCandidates = []string{EncodingGB18030, EncodingBig5}
+
+// getEncoding 根据编码名称获取对应的编码器，返回规范化后的编码名称
+func getEncoding(encodingName string) (encoding.Encoding, string, error) {
+	switch strings.ToLower(strings.TrimSpace(encodingName)) {
+	case "utf8", "utf-8", "utf-8-bom", "utf8bom":
+		// 解码时自动去掉BOM
+		return unicode.UTF8BOM, EncodingUTF8, nil
+	case "gbk", "gb2312", "cp936":
+		return simplifiedchinese.GBK, EncodingGBK, nil
+	case "gb18030":
+		return simplifiedchinese.GB18030, EncodingGB18030, nil
+	case "utf-16le", "utf16le", "utf-16", "utf16", "unicode":
+		// 有BOM时以BOM为准
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), EncodingUTF16LE, nil
+	case "utf-16be", "utf16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), EncodingUTF16BE, nil
+	case "big5", "big-5", "cp950":
+		return traditionalchinese.Big5, EncodingBig5, nil
+	default:
+		return nil, "", fmt.Errorf("不支持的编码格式: %s，支持auto、UTF-8、GBK、GB18030、UTF-16LE、UTF-16BE和Big5", encodingName)
+	}
+}
+
+// decodeText 按指定编码将文件内容解码为UTF-8文本，encodingName为auto或空时自动检测。
+// 返回解码后的文本和实际使用的编码名称
+func decodeText(data []byte, encodingName string) (string, string, error) {
+	if name := strings.ToLower(strings.TrimSpace(encodingName)); name == "" || name == EncodingAuto {
+		encodingName = detectEncoding(data)
+	}
+
+	enc, name, err := getEncoding(encodingName)
+	if err != nil {
+		return "", "", err
+	}
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", "", fmt.Errorf("按%s解码失败: %v", name, err)
+	}
+	return string(decoded), name, nil
+}
+
+// detectEncoding 猜测文件编码：先识别BOM，再检查是否为合法UTF-8或无BOM的UTF-16，
+// 最后对GB18030和Big5分别解码，选择非法字符最少、常用字比例最高的一种
+func detectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return EncodingUTF16BE
+	}
+
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	if name := detectUTF16WithoutBOM(data); name != "" {
+		return name
+	}
+
+	best, bestScore := EncodingGB18030, -1.0
+	for _, name := range autoEncodingCandidates {
+		enc, _, _ := getEncoding(name)
+		decoded, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			continue
+		}
+		if score := decodedTextScore(string(decoded)); score > bestScore {
+			best, bestScore = name, score
+		}
+	}
+	return best
+}
+
+// detectUTF16WithoutBOM 根据零字节的分布判断没有BOM的UTF-16文本：
+// 以ASCII为主的UTF-16文本中，每两个字节就有一个零字节，且集中在奇数位（小端序）或偶数位（大端序）
+func detectUTF16WithoutBOM(data []byte) string {
+	if len(data) < 4 || len(data)%2 != 0 {
+		return ""
+	}
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i+1 < len(data); i += 2 {
+		if data[i] == 0 {
+			evenZeros++
+		}
+		if data[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case oddZeros*10 >= pairs*3 && evenZeros*10 < pairs:
+		return EncodingUTF16LE
+	case evenZeros*10 >= pairs*3 && oddZeros*10 < pairs:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+// decodedTextScore 评估解码结果的可信度：非法字符扣分，ASCII和常用汉字（繁体字按对应的简体字判断）得分。
+// 错误的编码解码出的多为生僻字，得分明显较低
+func decodedTextScore(text string) float64 {
+	gbk := simplifiedchinese.GBK.NewEncoder()
+	total, score := 0, 0.0
+	for _, r := range text {
+		total++
+		switch {
+		case r == utf8.RuneError:
+			score -= 10
+		case r < utf8.RuneSelf:
+			score++
+		case isCommonHan(gbk, r):
+			score++
+		case r >= 0x3000 && r <= 0x303F, r >= 0xFF00 && r <= 0xFFEF:
+			// 中文标点和全角字符
+			score++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return score / float64(total)
+}
+
+// isCommonHan 判断字符是否为常用汉字，即其简体形式属于GB2312一级字库（GBK首字节0xB0-0xD7）
+func isCommonHan(gbk *encoding.Encoder, r rune) bool {
+	if simplified, ok := traditionalToSimplified[r]; ok {
+		r = simplified
+	}
+	if r < 0x4E00 || r > 0x9FFF {
+		return false
+	}
+	var dst [4]byte
+	var src [utf8.UTFMax]byte
+	n := utf8.EncodeRune(src[:], r)
+	nDst, _, err := gbk.Transform(dst[:], src[:n], true)
+	return err == nil && nDst == 2 && dst[0] >= 0xB0 && dst[0] <= 0xD7
+}
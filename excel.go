@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// openExcelFile 打开Excel工作簿，旧版.xls格式给出明确提示
+func openExcelFile(filePath string) (*excelize.File, error) {
+	if strings.ToLower(filepath.Ext(filePath)) == ".xls" {
+		return nil, fmt.Errorf("暂不支持旧版.xls格式，请在Excel中另存为.xlsx后重新导入")
+	}
+
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开Excel文件: %v", err)
+	}
+	return f, nil
+}
+
+// ListExcelSheets 列出Excel文件中的工作表名称
+func (e *ExamService) ListExcelSheets(filePath string) ([]string, error) {
+	f, err := openExcelFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return f.GetSheetList(), nil
+}
+
+// ParseExcelFile 解析Excel文件，sheetName为空时使用第一个工作表。
+// 有问题的行会被跳过，返回结果中的导入报告列出跳过的行
+func (e *ExamService) ParseExcelFile(filePath string, sheetName string, optionSeparator string, answerSeparator string) (ImportResult, error) {
+	return e.parseExcelFile(ParseExcelRequest{
+		FilePath:        filePath,
+		SheetName:       sheetName,
+		OptionSeparator: optionSeparator,
+		AnswerSeparator: answerSeparator,
+	})
+}
+
+// parseExcelFile 按请求中的设置解析Excel文件并生成逐行导入报告，Profile和Strict的含义与parseCSVFile相同
+func (e *ExamService) parseExcelFile(req ParseExcelRequest) (ImportResult, error) {
+	collector := newImportCollector(req.Strict)
+	sheetName := req.SheetName
+
+	f, err := openExcelFile(req.FilePath)
+	if err != nil {
+		return collector.result, err
+	}
+	defer f.Close()
+
+	// 确定要读取的工作表
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return collector.result, fmt.Errorf("Excel文件中没有工作表")
+	}
+	if sheetName == "" {
+		sheetName = sheets[0]
+	} else if idx, _ := f.GetSheetIndex(sheetName); idx == -1 {
+		return collector.result, fmt.Errorf("工作表不存在: %s，可选工作表: %s", sheetName, strings.Join(sheets, ", "))
+	}
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return collector.result, fmt.Errorf("读取工作表失败: %v", err)
+	}
+	if len(rows) == 0 {
+		return collector.result, fmt.Errorf("读取标题行失败: 工作表 %s 为空", sheetName)
+	}
+
+	columns, err := mapAnswerHeaders(rows[0], req.Profile)
+	if err != nil {
+		return collector.result, err
+	}
+
+	// 确定选项和答案分隔符，设置为auto时根据选项列推断
+	optionSeparator, answerSeparator, inferred := e.resolveAnswerSeparators(req.OptionSeparator, req.AnswerSeparator, rows[1:], columns["选项"])
+	collector.result.Separators = DetectedSeparators{
+		OptionSeparator: optionSeparator,
+		AnswerSeparator: answerSeparator,
+	}
+
+	// 读取数据行，Excel中常见的空白行直接跳过。GetRows会省略行尾的空单元格，因此不检查列数
+	for i, row := range rows[1:] {
+		if isBlankRow(row) {
+			continue
+		}
+		// 标题行为第1行
+		collector.add(i+2, e.parseAnswerRecord(row, columns, optionSeparator, answerSeparator, inferred))
+	}
+
+	result, err := collector.finish()
+	result.setSource(req.FilePath)
+	return result, err
+}
+
+// isBlankRow 判断一行是否所有单元格都为空
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseExcelRequest HTTP Excel解析请求结构
+type ParseExcelRequest struct {
+	FilePath        string `json:"filePath"`
+	SheetName       string `json:"sheetName"`         // 为空时使用第一个工作表
+	OptionSeparator string `json:"optionSeparator"`   // 选项分隔符，auto时根据选项内容推断
+	AnswerSeparator string `json:"answerSeparator"`   // 答案分隔符，auto时与选项分隔符相同
+	Profile         string `json:"profile,omitempty"` // 列映射方案名称
+	Strict          bool   `json:"strict,omitempty"`  // 严格模式：任意一行有问题即整体失败，默认跳过有问题的行
+}
+
+// ParseExcelResponse HTTP Excel解析响应结构
+type ParseExcelResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Sheets  []string     `json:"sheets,omitempty"` // 文件中的全部工作表，供前端选择
+	Results []AnswerItem `json:"results,omitempty"`
+
+	Separators  *DetectedSeparators `json:"separators,omitempty"`  // 实际使用的分隔符，供用户确认
+	Report      *ImportReport       `json:"report,omitempty"`      // 逐行导入报告
+	HeaderError *HeaderError        `json:"headerError,omitempty"` // 标题行缺少必需字段时的详细信息，供前端建立列映射
+}
+
+// handleParseExcel 处理HTTP Excel解析请求
+func handleParseExcel(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ParseExcelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	// 工作表列表获取失败时不影响解析结果的返回
+	sheets, _ := examService.ListExcelSheets(req.FilePath)
+
+	// 按请求中的列映射方案解析Excel文件，同时生成导入报告
+	result, err := examService.parseExcelFile(req)
+	if err != nil {
+		response := ParseExcelResponse{
+			Success:     false,
+			Message:     "Excel解析失败: " + err.Error(),
+			Sheets:      sheets,
+			Report:      &result.Report,
+			HeaderError: headerErrorOf(err),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// 返回解析结果
+	response := ParseExcelResponse{
+		Success:    true,
+		Message:    result.Report.Summary(),
+		Sheets:     sheets,
+		Separators: &result.Separators,
+		Results:    result.Items,
+		Report:     &result.Report,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListExcelSheetsRequest HTTP Excel工作表列表请求结构
+type ListExcelSheetsRequest struct {
+	FilePath string `json:"filePath"`
+}
+
+// ListExcelSheetsResponse HTTP Excel工作表列表响应结构
+type ListExcelSheetsResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message,omitempty"`
+	Sheets  []string `json:"sheets,omitempty"` // 文件中的全部工作表，按文件中的顺序排列
+}
+
+// handleListExcelSheets 处理HTTP Excel工作表列表请求，供前端在导入前选择工作表
+func handleListExcelSheets(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ListExcelSheetsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response := ListExcelSheetsResponse{Success: true}
+	sheets, err := examService.ListExcelSheets(req.FilePath)
+	if err != nil {
+		response = ListExcelSheetsResponse{Success: false, Message: err.Error()}
+	} else {
+		response.Sheets = sheets
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestListAndParseExcelSheets(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetName("Sheet1", "第一章")
+	f.NewSheet("第二章")
+	rows := map[string][]any{
+		"第一章": {"单选题", "光速约为多少", "A. 30万千米每秒|B. 340米每秒", "A"},
+		"第二章": {"单选题", "水的沸点", "A. 100℃|B. 50℃", "A"},
+	}
+	for sheet, row := range rows {
+		f.SetSheetRow(sheet, "A1", &[]any{"类型", "题目", "选项", "答案"})
+		f.SetSheetRow(sheet, "A2", &row)
+	}
+	path := filepath.Join(t.TempDir(), "题库.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := json.Marshal(ListExcelSheetsRequest{FilePath: path})
+	rec := httptest.NewRecorder()
+	handleListExcelSheets(rec, httptest.NewRequest(http.MethodPost, "/api/list-excel-sheets", bytes.NewReader(body)))
+	var response ListExcelSheetsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Success || !slices.Equal(response.Sheets, []string{"第一章", "第二章"}) {
+		t.Fatalf("工作表列表 = %+v", response)
+	}
+
+	e := &ExamService{}
+	result, err := e.ParseExcelFile(path, "第二章", "|", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Question != "水的沸点" {
+		t.Errorf("导入第二章 = %+v", result.Items)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import "strings"
+
+// 得分最高的字段
+const (
+	MatchFieldQuestion = "question"
+	MatchFieldOption   = "option"
+	MatchFieldAnswer   = "answer"
+)
+
+// 评分方式，对应calculateOverlapScore中的各个分支
+const (
+	MatchMethodNone      = "none"      // 没有任何字段得分
+	MatchMethodExact     = "exact"     // 完全相同
+	MatchMethodContains  = "contains"  // 字段包含查询文本
+	MatchMethodContained = "contained" // 查询文本包含字段
+	MatchMethodWeighted  = "weighted"  // 编辑距离、关键词、字符相似度加权
+	MatchMethodFallback  = "fallback"  // 没有共同关键词，仅按编辑距离折算
+	MatchMethodBM25      = "bm25"      // BM25评分
+)
+
+// ScoreExplanation 搜索结果的评分明细
+type ScoreExplanation struct {
+	Field     string `json:"field,omitempty"`     // 得分最高的字段：question/option/answer
+	FieldText string `json:"fieldText,omitempty"` // 得分最高字段的原文
+	Method    string `json:"method"`              // 评分方式
+
+	Containment       float64 `json:"containment"`       // 包含匹配得分，未发生包含时为0
+	EditSimilarity    float64 `json:"editSimilarity"`    // 编辑距离相似度
+	KeywordSimilarity float64 `json:"keywordSimilarity"` // 关键词相似度
+	CharSimilarity    float64 `json:"charSimilarity"`    // 字符相似度
+
+	FieldWeight float64  `json:"fieldWeight"`     // 字段权重，选项为OptionWeight，其余为1
+	RawScore    float64  `json:"rawScore"`        // 乘以字段权重之前的得分
+	Score       float64  `json:"score"`           // 最终得分
+	Terms       []string `json:"terms,omitempty"` // BM25模式下题目命中的查询词项
+}
+
+// explainOverlap 重新计算得分最高字段的各项相似度，与calculateOverlapScore的判断顺序保持一致。
+// 各项相似度无论是否参与最终评分都会给出，便于对比
+func (e *ExamService) explainOverlap(query, field, fieldText, text string, cfg MatcherConfig) *ScoreExplanation {
+	explanation := &ScoreExplanation{
+		Field:       field,
+		FieldText:   fieldText,
+		Method:      MatchMethodNone,
+		FieldWeight: 1,
+	}
+	if field == "" || query == "" || text == "" {
+		return explanation
+	}
+	if field == MatchFieldOption {
+		explanation.FieldWeight = cfg.OptionWeight
+	}
+
+	explanation.EditSimilarity = e.calculateEditSimilarity(query, text)
+	explanation.KeywordSimilarity = e.calculateKeywordSimilarity(query, text)
+	explanation.CharSimilarity = e.calculateCharSimilarity(query, text)
+	explanation.RawScore, _ = e.calculateOverlapScore(query, text, cfg)
+
+	switch {
+	case query == text:
+		explanation.Method = MatchMethodExact
+		explanation.Containment = explanation.RawScore
+	case strings.Contains(text, query):
+		explanation.Method = MatchMethodContains
+		explanation.Containment = explanation.RawScore
+	case strings.Contains(query, text):
+		explanation.Method = MatchMethodContained
+		explanation.Containment = explanation.RawScore
+	case len(e.findCommonWords(query, text)) == 0:
+		explanation.Method = MatchMethodFallback
+	default:
+		explanation.Method = MatchMethodWeighted
+	}
+	if explanation.RawScore == 0 {
+		explanation.Method = MatchMethodNone
+	}
+
+	return explanation
+}
@@ -6,9 +6,10 @@ const API_BASE_URL = 'http://localhost:8088'
  * 搜索答案
  * @param {string} query - 搜索查询
  * @param {Object} filters - 过滤条件
+ * @param {boolean} explain - 是否在每个结果中附带评分明细，用于排查排序问题
  * @returns {Promise<Array>} 搜索结果
  */
-export async function searchAnswers(query, filters = {}) {
+export async function searchAnswers(query, filters = {}, explain = false) {
   try {
     const response = await fetch(`${API_BASE_URL}/api/search`, {
       method: 'POST',
@@ -17,7 +18,8 @@ export async function searchAnswers(query, filters = {}) {
       },
       body: JSON.stringify({
         query,
-        filters
+        filters,
+        explain
       })
     })
 
@@ -41,7 +43,7 @@ export async function searchAnswers(query, filters = {}) {
 /**
  * 解析CSV文件
  * @param {string} filePath - 文件路径
- * @param {string} encoding - 文件编码
+ * @param {string} encoding - 文件编码，auto为自动检测
  * @param {string} optionSeparator - 选项分隔符
  * @param {string} answerSeparator - 答案分隔符
  * @returns {Promise<Array>} 解析结果
@@ -78,6 +80,78 @@ export async function parseCSVFile(filePath, encoding, optionSeparator, answerSe
   }
 }
 
+/**
+ * 解析Excel文件
+ * @param {string} filePath - 文件路径
+ * @param {string} sheetName - 工作表名称，为空时使用第一个工作表
+ * @param {string} optionSeparator - 选项分隔符
+ * @param {string} answerSeparator - 答案分隔符
+ * @returns {Promise<Array>} 解析结果
+ */
+export async function parseExcelFile(filePath, sheetName, optionSeparator, answerSeparator) {
+  try {
+    const response = await fetch(`${API_BASE_URL}/api/parse-excel`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({
+        filePath,
+        sheetName,
+        optionSeparator,
+        answerSeparator
+      })
+    })
+
+    if (!response.ok) {
+      throw new Error(`HTTP请求失败: ${response.status} ${response.statusText}`)
+    }
+
+    const data = await response.json()
+    
+    if (!data.success) {
+      throw new Error(data.message || 'Excel解析失败')
+    }
+
+    return data.results || []
+  } catch (error) {
+    console.error('Excel解析失败:', error)
+    throw error
+  }
+}
+
+/**
+ * 列出Excel文件中的工作表
+ * @param {string} filePath - 文件路径
+ * @returns {Promise<Array<string>>} 工作表名称，按文件中的顺序排列
+ */
+export async function listExcelSheets(filePath) {
+  try {
+    const response = await fetch(`${API_BASE_URL}/api/list-excel-sheets`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ filePath })
+    })
+
+    if (!response.ok) {
+      throw new Error(`HTTP请求失败: ${response.status} ${response.statusText}`)
+    }
+
+    const data = await response.json()
+
+    if (!data.success) {
+      throw new Error(data.message || '读取工作表失败')
+    }
+
+    return data.sheets || []
+  } catch (error) {
+    console.error('读取工作表失败:', error)
+    throw error
+  }
+}
+
 /**
  * 设置全局答案
  * @param {Array} answers - 答案数组
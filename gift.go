@@ -0,0 +1,276 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// giftBlock GIFT文件中以空行分隔的一段，line为该段第一行的行号
+type giftBlock struct {
+	line int
+	text string
+}
+
+// giftAnswer 答案块中以=或~开头的一项
+type giftAnswer struct {
+	correct bool    // 以=开头
+	weight  float64 // ~%50%这类写法的得分百分比
+	text    string
+}
+
+// parseGIFT 逐题解析GIFT文本，报告中的行号为每道题第一行的行号
+func parseGIFT(content string, strict bool) (ImportResult, error) {
+	collector := newImportCollector(strict)
+
+	category := ""
+	for _, block := range splitGIFTBlocks(content) {
+		text := block.text
+		// $CATEGORY:只用于指定之后题目的分类，可能与题目写在同一段
+		if strings.HasPrefix(text, "$CATEGORY:") {
+			path, rest, _ := strings.Cut(text, "\n")
+			category = moodleCategoryName(strings.TrimPrefix(path, "$CATEGORY:"))
+			if text = strings.TrimSpace(rest); text == "" {
+				continue
+			}
+		}
+
+		item, reason := parseGIFTQuestion(text)
+		if reason != "" {
+			collector.skip(block.line, reason)
+			continue
+		}
+		item.Category = category
+		collector.add(block.line, item)
+	}
+
+	return collector.finish()
+}
+
+// splitGIFTBlocks 按空行将GIFT文本拆分为段，跳过//开头的注释行。答案块{}内的空行不分段
+func splitGIFTBlocks(content string) []giftBlock {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	blocks := []giftBlock{}
+	lines := []string{}
+	start, depth := 0, 0
+	flush := func() {
+		if len(lines) > 0 {
+			blocks = append(blocks, giftBlock{line: start, text: strings.TrimSpace(strings.Join(lines, "\n"))})
+		}
+		lines = lines[:0]
+	}
+	for i, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if trimmed == "" && depth == 0 {
+			flush()
+			continue
+		}
+		if len(lines) == 0 {
+			start = i + 1
+		}
+		lines = append(lines, line)
+		depth += strings.Count(giftMask(line), "{") - strings.Count(giftMask(line), "}")
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	flush()
+	return blocks
+}
+
+// giftMask 将转义字符替换为空格，便于查找未转义的特殊字符，长度与原文相同
+func giftMask(s string) string {
+	b := []byte(s)
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) {
+			b[i], b[i+1] = ' ', ' '
+			i++
+		}
+	}
+	return string(b)
+}
+
+// giftUnescape 还原GIFT转义字符，如\=、\{、\n
+func giftUnescape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				sb.WriteByte('\n')
+			} else {
+				sb.WriteByte(s[i])
+			}
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+// giftText 将GIFT中的一段文字转换为纯文本，[html]格式的去掉标签
+func giftText(s string, isHTML bool) string {
+	s = giftUnescape(s)
+	if isHTML {
+		return htmlToText(s)
+	}
+	return strings.TrimSpace(s)
+}
+
+// parseGIFTQuestion 将一道GIFT题目转换为答案项，题型不支持时返回原因
+func parseGIFTQuestion(text string) (AnswerItem, string) {
+	item := AnswerItem{Options: []string{}, Answer: []string{}}
+
+	// ::标题::
+	title := ""
+	if strings.HasPrefix(text, "::") {
+		if end := strings.Index(giftMask(text[2:]), "::"); end >= 0 {
+			title = strings.TrimSpace(giftUnescape(text[2 : 2+end]))
+			text = strings.TrimSpace(text[4+end:])
+		}
+	}
+
+	// [html]、[markdown]等格式标记
+	isHTML := false
+	for _, tag := range []string{"[html]", "[moodle]", "[markdown]", "[plain]"} {
+		if strings.HasPrefix(strings.ToLower(text), tag) {
+			isHTML = tag == "[html]"
+			text = text[len(tag):]
+			break
+		}
+	}
+
+	masked := giftMask(text)
+	open := strings.Index(masked, "{")
+	if open < 0 {
+		return item, "不支持的题型: description"
+	}
+	closing := strings.Index(masked[open:], "}")
+	if closing < 0 {
+		return item, "答案块缺少}"
+	}
+	closing += open
+
+	// 答案块后面还有文字时为填空题（missing word），用下划线表示空缺处
+	before, after := giftText(text[:open], isHTML), giftText(text[closing+1:], isHTML)
+	item.Question = before
+	if after != "" {
+		item.Question = strings.TrimSpace(before + " ____ " + after)
+	}
+	if item.Question == "" {
+		item.Question = title
+	}
+
+	body := text[open+1 : closing]
+	// ####之后为总体反馈，作为答案解析
+	if i := strings.Index(giftMask(body), "####"); i >= 0 {
+		item.Explanation = giftText(body[i+4:], isHTML)
+		body = body[:i]
+	}
+	body = strings.TrimSpace(body)
+
+	switch {
+	case body == "":
+		return item, "不支持的题型: essay"
+	case strings.HasPrefix(body, "#"):
+		return item, "不支持的题型: numerical"
+	}
+
+	// 判断题：{T}、{TRUE}、{F}、{FALSE}，#之后为反馈
+	judge := strings.ToUpper(strings.TrimSpace(body))
+	if i := strings.Index(giftMask(body), "#"); i >= 0 {
+		judge = strings.ToUpper(strings.TrimSpace(body[:i]))
+	}
+	switch judge {
+	case "T", "TRUE", "F", "FALSE":
+		item.Type = QuestionTypeJudge
+		item.Options = []string{judgeTrue, judgeFalse}
+		if judge == "T" || judge == "TRUE" {
+			item.Answer, item.AnswerKeys = []string{judgeTrue}, []string{optionKey(0)}
+		} else {
+			item.Answer, item.AnswerKeys = []string{judgeFalse}, []string{optionKey(1)}
+		}
+		return item, ""
+	}
+
+	answers, ok := splitGIFTAnswers(body)
+	if !ok {
+		return item, "无法识别的答案块: " + body
+	}
+
+	hasWrong, weighted := false, false
+	for _, answer := range answers {
+		if strings.Contains(giftMask(answer.text), "->") {
+			return item, "不支持的题型: matching"
+		}
+		if !answer.correct {
+			hasWrong = true
+			if answer.weight > 0 {
+				weighted = true
+			}
+		}
+	}
+
+	// 全部以=开头为简答题，每个答案都可接受
+	if !hasWrong {
+		item.Type = QuestionTypeBlank
+		for _, answer := range answers {
+			item.Answer = append(item.Answer, giftText(answer.text, isHTML))
+		}
+		return item, ""
+	}
+
+	// 选择题：=为正确答案，~%50%这类带正分的也是正确答案（多选）。
+	// 选项是完整文本，答案字母按选项顺序确定，不再从答案文本解析
+	for i, answer := range answers {
+		option := giftText(answer.text, isHTML)
+		item.Options = append(item.Options, option)
+		if answer.correct || answer.weight > 0 {
+			item.Answer = append(item.Answer, option)
+			if key := optionKey(i); key != "" {
+				item.AnswerKeys = append(item.AnswerKeys, key)
+			}
+		}
+	}
+	item.Type = QuestionTypeSingle
+	if weighted || len(item.Answer) > 1 {
+		item.Type = QuestionTypeMultiple
+	}
+	return item, ""
+}
+
+// splitGIFTAnswers 将答案块拆分为以=或~开头的各项，去掉每项的#反馈并解析%权重%。
+// 答案块不以=或~开头时返回false
+func splitGIFTAnswers(body string) ([]giftAnswer, bool) {
+	masked := giftMask(body)
+	if masked == "" || (masked[0] != '=' && masked[0] != '~') {
+		return nil, false
+	}
+
+	answers := []giftAnswer{}
+	start := 0
+	for i := 1; i <= len(masked); i++ {
+		if i < len(masked) && masked[i] != '=' && masked[i] != '~' {
+			continue
+		}
+		answer := giftAnswer{correct: masked[start] == '='}
+		text := body[start+1 : i]
+		if j := strings.Index(giftMask(text), "#"); j >= 0 {
+			text = text[:j]
+		}
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, "%") {
+			if end := strings.Index(text[1:], "%"); end >= 0 {
+				answer.weight, _ = strconv.ParseFloat(text[1:1+end], 64)
+				text = strings.TrimSpace(text[2+end:])
+			}
+		}
+		answer.text = text
+		answers = append(answers, answer)
+		start = i
+	}
+	return answers, true
+}
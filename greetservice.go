@@ -5,11 +5,13 @@ import (
 	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image"
 	"image/png"
 	"io"
 	"log"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -19,10 +21,6 @@ import (
 	"time"
 	"unicode/utf8"
 
-	"golang.org/x/text/encoding"
-	"golang.org/x/text/encoding/simplifiedchinese"
-	"golang.org/x/text/transform"
-
 	"github.com/kbinani/screenshot"
 	"github.com/wailsapp/wails/v3/pkg/application"
 )
@@ -56,16 +54,30 @@ type ScreenshotArea struct {
 
 // AnswerItem 答案项
 type AnswerItem struct {
+	ID string `json:"id,omitempty"` // 题目ID，保存到题库时自动分配，之后保持不变
+
 	Type     string   `json:"type"`     // 题目类型
 	Question string   `json:"question"` // 题目内容
 	Options  []string `json:"options"`  // 选项
 	Answer   []string `json:"answer"`   // 答案
+
+	Explanation string `json:"explanation,omitempty"` // 答案解析
+	Category    string `json:"category,omitempty"`    // 分类
+	Difficulty  string `json:"difficulty,omitempty"`  // 难度
+
+	Chapter string   `json:"chapter,omitempty"` // 所属章节
+	Tags    []string `json:"tags,omitempty"`    // 标签
+	Source  string   `json:"source,omitempty"`  // 来源文档，从文件导入且没有来源列时为文件名
+
+	AnswerKeys []string `json:"answerKeys,omitempty"` // 答案对应的选项字母，如["A","C"]
+	Warnings   []string `json:"warnings,omitempty"`   // 导入时发现的问题，如答案字母没有对应的选项
 }
 
 // 校验过程可能返回类型
 type HeaderError struct {
-	Missing []string // 缺失字段
-	Extra   []string // 多余字段
+	Missing []string `json:"missing"`           // 缺失字段
+	Extra   []string `json:"extra"`             // 多余字段，即未能识别的标题
+	Headers []string `json:"headers,omitempty"` // 文件中的完整标题行，供前端建立列映射
 }
 
 func (e HeaderError) Error() string {
@@ -82,11 +94,15 @@ func (e HeaderError) Error() string {
 // SearchResult 搜索结果
 type SearchResult struct {
 	Item            AnswerItem       `json:"item"`
+	Bank            string           `json:"bank,omitempty"`  // 所属题库
 	Score           float64          `json:"score"`           // 匹配度
 	Matched         string           `json:"matched"`         // 匹配的文本
 	QuestionMatches []int            `json:"questionMatches"` // 题目匹配位置
 	OptionMatches   map[string][]int `json:"optionMatches"`   // 选项匹配位置，key为选项文本
 	AnswerMatches   []int            `json:"answerMatches"`   // 答案匹配位置（不使用）
+
+	// 评分明细，仅在请求explain时返回
+	Explanation *ScoreExplanation `json:"explanation,omitempty"`
 }
 
 // FileDialogResult 文件对话框结果
@@ -232,135 +248,222 @@ func (e *ExamService) OpenFileDialog(title string, fileType string) (FileDialogR
 	}, nil
 }
 
-// ReadFileContent 读取文件内容
-// getEncoding 根据编码名称获取对应的编码器
-func getEncoding(encodingName string) (encoding.Encoding, error) {
-	switch strings.ToLower(encodingName) {
-	case "utf8", "utf-8":
-		return nil, nil // UTF-8是默认编码
-	case "gbk", "gb2312":
-		return simplifiedchinese.GBK, nil
-	default:
-		return nil, fmt.Errorf("不支持的编码格式: %s，仅支持UTF-8和GBK", encodingName)
+// SaveFileDialog 打开保存文件对话框，defaultName为建议的文件名
+func (e *ExamService) SaveFileDialog(title string, fileType string, defaultName string) (FileDialogResult, error) {
+	// 使用Wails v3的文件对话框API
+	dialog := application.SaveFileDialog()
+
+	// 设置标题和建议的文件名
+	dialog.SetMessage(title)
+	dialog.SetFilename(defaultName)
+	dialog.CanCreateDirectories(true)
+
+	// 设置文件过滤器
+	switch fileType {
+	case "csv":
+		dialog.AddFilter("CSV文件", "*.csv")
+	case "excel":
+		dialog.AddFilter("Excel文件", "*.xlsx")
+	case "json":
+		dialog.AddFilter("题库文件", "*.json;*.jsonl")
 	}
-}
 
-func (e *ExamService) ReadFileContent(filePath string, encoding string) (string, error) {
-	file, err := os.Open(filePath)
+	// 尝试附加到主窗口（如果可用）
+	app := application.Get()
+	if app != nil {
+		windows := app.Window.GetAll()
+		if len(windows) > 0 {
+			dialog.AttachToWindow(windows[0])
+		}
+	}
+
+	filePath, err := dialog.PromptForSingleSelection()
 	if err != nil {
-		return "", fmt.Errorf("无法打开文件: %v", err)
+		return FileDialogResult{
+			FilePath: "",
+			Success:  false,
+			Error:    fmt.Sprintf("打开保存对话框失败: %v", err),
+		}, nil
 	}
-	defer file.Close()
 
-	var reader io.Reader = file
+	// 如果用户取消了保存，filePath为空
+	if filePath == "" {
+		return FileDialogResult{
+			FilePath: "",
+			Success:  false,
+			Error:    "用户取消了保存",
+		}, nil
+	}
 
-	// 根据编码处理文件
-	if encoding != "utf8" && encoding != "utf-8" {
-		enc, err := getEncoding(encoding)
-		if err != nil {
-			return "", fmt.Errorf("编码设置错误: %v", err)
-		}
+	return FileDialogResult{
+		FilePath: filePath,
+		Success:  true,
+	}, nil
+}
 
-		if enc != nil {
-			reader = transform.NewReader(file, enc.NewDecoder())
-		}
+// ReadFileContent 读取文件内容，encoding为auto时自动检测编码
+func (e *ExamService) ReadFileContent(filePath string, encoding string) (string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("无法打开文件: %v", err)
 	}
 
-	// 读取文件内容
-	content, err := io.ReadAll(reader)
+	content, _, err := decodeText(data, encoding)
 	if err != nil {
-		return "", fmt.Errorf("读取文件失败: %v", err)
+		return "", fmt.Errorf("编码设置错误: %v", err)
 	}
+	return content, nil
+}
+
+// ParseCSVFile 解析CSV文件，encoding为auto时自动检测文件编码，字段分隔符自动检测，
+// 选项和答案分隔符为auto时根据选项列推断。有问题的行会被跳过，返回结果中的导入报告列出跳过的行
+func (e *ExamService) ParseCSVFile(filePath string, encoding string, optionSeparator string, answerSeparator string) (ImportResult, error) {
+	return e.parseCSVFile(ParseCSVRequest{
+		FilePath:        filePath,
+		Encoding:        encoding,
+		OptionSeparator: optionSeparator,
+		AnswerSeparator: answerSeparator,
+	})
+}
 
-	return string(content), nil
+// csvRow CSV中的一条记录及其起始行号
+type csvRow struct {
+	line   int
+	record []string
 }
 
-// ParseCSVFile 解析CSV文件
-func (e *ExamService) ParseCSVFile(filePath string, encoding string, optionSeparator string, answerSeparator string) ([]AnswerItem, error) {
-	var answers []AnswerItem
+// parseCSVFile 按请求中的设置解析CSV文件并生成逐行导入报告。
+// Profile为列映射方案名称，为空时按字段名和别名识别各列；Strict为true时任意一行有问题即整体失败，否则跳过有问题的行
+func (e *ExamService) parseCSVFile(req ParseCSVRequest) (ImportResult, error) {
+	collector := newImportCollector(req.Strict)
 
-	// 打开文件
-	f, err := os.Open(filePath)
+	// 读取文件
+	data, err := os.ReadFile(req.FilePath)
 	if err != nil {
-		return nil, fmt.Errorf("无法打开文件: %v", err)
+		return collector.result, fmt.Errorf("无法打开文件: %v", err)
 	}
-	defer f.Close()
 
-	// 解码器处理
-	var reader io.Reader
-	switch strings.ToLower(encoding) {
-	case "gbk", "gb2312":
-		reader = transform.NewReader(f, simplifiedchinese.GBK.NewDecoder())
-	case "utf-8", "utf8":
-		reader = f
-	default:
-		return nil, fmt.Errorf("不支持的编码格式: %s", encoding)
+	// 按指定编码解码，auto时自动检测
+	content, usedEncoding, err := decodeText(data, req.Encoding)
+	if err != nil {
+		return collector.result, err
 	}
+	collector.result.Encoding = usedEncoding
 
-	csvReader := csv.NewReader(reader)
+	// 确定字段分隔符，未指定时自动检测
+	delimiter, err := e.resolveDelimiter(req.Delimiter, content)
+	if err != nil {
+		return collector.result, err
+	}
+
+	csvReader := csv.NewReader(strings.NewReader(content))
+	csvReader.Comma = delimiter
 	csvReader.TrimLeadingSpace = true
+	// 列数不一致的行由下面逐行检查，不中断整个文件的读取
+	csvReader.FieldsPerRecord = -1
 
 	// 读取标题行
 	headers, err := csvReader.Read()
 	if err != nil {
-		return nil, fmt.Errorf("读取标题行失败: %v", err)
+		return collector.result, fmt.Errorf("读取标题行失败: %v", err)
 	}
 
-	expected := map[string]int{"类型": -1, "题目": -1, "选项": -1, "答案": -1}
-	for i, h := range headers {
-		if _, ok := expected[h]; ok {
-			expected[h] = i
-		}
-	}
-
-	// 检查缺失字段
-	var missing []string
-	for key, idx := range expected {
-		if idx == -1 {
-			missing = append(missing, key)
-		}
-	}
-	if len(missing) > 0 {
-		return nil, fmt.Errorf("缺少字段: %s", strings.Join(missing, ", "))
+	columns, err := mapAnswerHeaders(headers, req.Profile)
+	if err != nil {
+		return collector.result, err
 	}
 
-	// 读取数据行
+	// 读取全部数据行，格式错误的行直接记入报告
+	rows := []csvRow{}
 	for {
 		record, err := csvReader.Read()
 		if err == io.EOF {
 			break
 		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			collector.skip(parseErr.StartLine, "CSV格式错误: "+parseErr.Err.Error())
+			continue
+		}
 		if err != nil {
-			return nil, fmt.Errorf("读取数据失败: %v", err)
+			return collector.result, fmt.Errorf("读取数据失败: %v", err)
 		}
-
-		answer := AnswerItem{
-			Type:     strings.TrimSpace(record[expected["类型"]]),
-			Question: strings.TrimSpace(record[expected["题目"]]),
-			Options:  []string{},
-			Answer:   []string{},
+		if isBlankRow(record) {
+			continue
 		}
 
-		// 拆分选项
-		optionsStr := record[expected["选项"]]
-		if optionSeparator != "" {
-			separator := e.parseSeparator(optionSeparator)
-			answer.Options = strings.Split(optionsStr, separator)
-		} else {
-			answer.Options = []string{optionsStr}
+		line, _ := csvReader.FieldPos(0)
+		rows = append(rows, csvRow{line: line, record: record})
+	}
+
+	// 确定选项和答案分隔符，设置为auto时根据选项列推断
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		records = append(records, row.record)
+	}
+	optionSeparator, answerSeparator, inferred := e.resolveAnswerSeparators(req.OptionSeparator, req.AnswerSeparator, records, columns["选项"])
+	collector.result.Separators = DetectedSeparators{
+		Delimiter:       e.escapeSeparator(string(delimiter)),
+		OptionSeparator: optionSeparator,
+		AnswerSeparator: answerSeparator,
+	}
+
+	for _, row := range rows {
+		if len(row.record) != len(headers) {
+			collector.skip(row.line, fmt.Sprintf("列数与标题行不一致: 应为%d列，实际为%d列", len(headers), len(row.record)))
+			continue
 		}
+		collector.add(row.line, e.parseAnswerRecord(row.record, columns, optionSeparator, answerSeparator, inferred))
+	}
+
+	result, err := collector.finish()
+	result.setSource(req.FilePath)
+	return result, err
+}
 
-		// 拆分答案
-		answerStr := record[expected["答案"]]
-		if answerStr != "" {
-			separator := e.parseSeparator(answerSeparator)
+// cellAt 安全读取一行中的单元格，越界时返回空字符串
+func cellAt(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return record[idx]
+}
+
+// parseAnswerRecord 按字段映射和分隔符将一行数据转换为答案项，
+// rowFallback为true时选项按该分隔符拆不开的行改用该行自身推断的分隔符
+func (e *ExamService) parseAnswerRecord(record []string, columns map[string]int, optionSeparator string, answerSeparator string, rowFallback bool) AnswerItem {
+	answer := AnswerItem{
+		Type:     strings.TrimSpace(cellAt(record, columns["类型"])),
+		Question: strings.TrimSpace(cellAt(record, columns["题目"])),
+		Options:  []string{},
+		Answer:   []string{},
+
+		Explanation: strings.TrimSpace(cellAt(record, columns["解析"])),
+		Category:    strings.TrimSpace(cellAt(record, columns["分类"])),
+		Difficulty:  strings.TrimSpace(cellAt(record, columns["难度"])),
+
+		Chapter: strings.TrimSpace(cellAt(record, columns["章节"])),
+		Tags:    splitTags(cellAt(record, columns["标签"])),
+		Source:  strings.TrimSpace(cellAt(record, columns["来源"])),
+	}
+
+	// 拆分选项，没有选项的题目（如判断题、填空题）保持选项为空
+	if optionsStr := cellAt(record, columns["选项"]); strings.TrimSpace(optionsStr) != "" {
+		answer.Options = splitOptions(optionsStr, e.parseSeparator(optionSeparator), rowFallback)
+	}
+
+	// 拆分答案
+	answerStr := cellAt(record, columns["答案"])
+	if answerStr != "" {
+		if separator := e.parseSeparator(answerSeparator); separator != "" {
 			answer.Answer = strings.Split(answerStr, separator)
+		} else {
+			answer.Answer = []string{answerStr}
 		}
-
-		answers = append(answers, answer)
 	}
 
-	return answers, nil
+	// 将"A"、"AB"这类只写了字母的答案对应到完整选项
+	return resolveAnswerKeys(answer)
 }
 
 // parseSeparator 解析分隔符，支持转义字符
@@ -726,178 +829,252 @@ func (e *ExamService) performOnlineOCR(imageData []byte, config OCRConfig) (stri
 	return strings.TrimSpace(result.ParsedResults[0].ParsedText), nil
 }
 
-// normalizeText 标准化文本，移除或替换特殊字符以提高匹配率
+// normalizeText 标准化文本，折叠全角半角和繁简字形，移除或替换特殊字符以提高匹配率
 func (e *ExamService) normalizeText(text string) string {
-	// 移除常见的标点符号和特殊字符，但保留中文字符
-	// 这些字符在OCR识别中经常出现，但在语义匹配时应该被忽略
-	replacer := strings.NewReplacer(
-		"(", "", ")", "", "[", "", "]", "", "{", "", "}", "",
-		"（", "", "）", "", "【", "", "】", "", "《", "", "》", "",
-		"\"", "", "'", "", "`", "", "~", "", "!", "", "@", "",
-		"#", "", "$", "", "%", "", "^", "", "&", "", "*", "",
-		"+", "", "=", "", "|", "", "\\", "", "/", "", "?", "",
-		"<", "", ">", "", ",", "", ".", "", ";", "", ":", "",
-		"、", "", "，", "", "。", "", "；", "", "：", "", "！", "",
-		"？", "", "…", "", "—", "", "－", "", "·", "", "·", "",
-		"　", " ", "  ", " ", // 多个空格替换为单个空格
-	)
-
-	normalized := replacer.Replace(text)
-
-	// 移除多余的空格
-	normalized = strings.TrimSpace(normalized)
-
-	// 将多个连续空格替换为单个空格
-	for strings.Contains(normalized, "  ") {
-		normalized = strings.ReplaceAll(normalized, "  ", " ")
-	}
-
+	normalized, _ := normalizeWithPositions(text)
 	return normalized
 }
 
 // SearchAnswers 搜索答案
 // AccuracyFilters 准确度筛选参数
 type AccuracyFilters struct {
-	High   bool `json:"high"`   // 高准确率 (≥80%)
-	Medium bool `json:"medium"` // 中准确率 (50%-79%)
-	Low    bool `json:"low"`    // 低准确率 (<50%)
+	High   bool `json:"high"`   // 高准确率 (默认≥80%)
+	Medium bool `json:"medium"` // 中准确率 (默认50%-79%)
+	Low    bool `json:"low"`    // 低准确率 (默认<50%)
+}
+
+// accepts 判断分数是否满足准确度筛选条件，分段界限由匹配参数决定
+func (f AccuracyFilters) accepts(score float64, cfg MatcherConfig) bool {
+	// 如果所有过滤器都为false，显示所有结果
+	if !f.High && !f.Medium && !f.Low {
+		return true
+	}
+
+	// 否则按过滤器筛选
+	if score >= cfg.HighThreshold {
+		return f.High
+	} else if score >= cfg.MediumThreshold {
+		return f.Medium
+	}
+	return f.Low
 }
 
 func (e *ExamService) SearchAnswers(answers []AnswerItem, query string, filters AccuracyFilters) ([]SearchResult, error) {
+	req := SearchRequest{Query: query, Filters: SearchFilters{AccuracyFilters: filters}}
+	return e.searchTargets([]searchTarget{{answers: answers}}, req)
+}
+
+// searchTarget 一次搜索涉及的题库及其题目
+type searchTarget struct {
+	bank    string
+	answers []AnswerItem
+	index   *searchIndex // 为空时全量扫描
+	allowed []bool       // 限定搜索范围时标记范围内的题目，为nil时不限
+}
+
+// Search 按请求搜索已保存的题库，可指定单个、多个或全部题库
+func (e *ExamService) Search(req SearchRequest) ([]SearchResult, error) {
+	targets, err := resolveSearchTargets(req.Banks, req.AllBanks)
+	if err != nil {
+		return nil, err
+	}
+	return e.searchTargets(targets, req)
+}
+
+// searchTargets 在多个题库中搜索，结果统一按匹配度排序
+func (e *ExamService) searchTargets(targets []searchTarget, req SearchRequest) ([]SearchResult, error) {
 	results := []SearchResult{}
+	filters := req.Filters.AccuracyFilters
+	cfg := currentMatcherConfig()
+
+	// 先按章节、标签等缩小范围，再在范围内搜索
+	if req.Filters.scoped() {
+		scoped := make([]searchTarget, 0, len(targets))
+		for _, target := range targets {
+			scoped = append(scoped, target.scope(req.Filters))
+		}
+		targets = scoped
+	}
+
+	switch req.ScoringMode {
+	case "", ScoringModeHeuristic, ScoringModeBM25:
+	default:
+		return nil, fmt.Errorf("不支持的评分模式: %s", req.ScoringMode)
+	}
 
 	// 预处理查询文本，移除特殊字符
-	normalizedQuery := e.normalizeText(query)
+	normalizedQuery := e.normalizeText(req.Query)
 	normalizedQuery = strings.ToLower(strings.TrimSpace(normalizedQuery))
 
 	// 如果查询为空，返回所有答案
 	if normalizedQuery == "" {
 		log.Println("查询为空，返回所有答案")
-		for _, answer := range answers {
-			results = append(results, SearchResult{
-				Item:            answer,
-				Score:           0.5, // 给予中等匹配度
-				Matched:         "全部结果",
-				QuestionMatches: []int{},
-				OptionMatches:   make(map[string][]int),
-				AnswerMatches:   []int{},
-			})
+		for _, target := range targets {
+			for i, answer := range target.answers {
+				if target.allowed != nil && !target.allowed[i] {
+					continue
+				}
+				results = append(results, SearchResult{
+					Item:            answer,
+					Bank:            target.bank,
+					Score:           0.5, // 给予中等匹配度
+					Matched:         "全部结果",
+					QuestionMatches: []int{},
+					OptionMatches:   make(map[string][]int),
+					AnswerMatches:   []int{},
+				})
+			}
 		}
 		return results, nil
 	}
 
 	// 如果答案数据为空，返回空结果
-	if len(answers) == 0 {
+	total := 0
+	for _, target := range targets {
+		total += len(target.answers)
+	}
+	if total == 0 {
 		log.Println("答案数据为空")
 		return results, nil
 	}
 
 	// 记录所有可能的匹配结果
 	allPossibleMatches := []SearchResult{}
+	queryTerms := e.indexTerms(normalizedQuery)
 
-	for _, answer := range answers {
-		question := answer.Question
-		// 预处理题目文本
-		normalizedQuestion := e.normalizeText(question)
-		questionLower := strings.ToLower(normalizedQuestion)
-		score := 0.0
-		matched := ""
-		maxScore := 0.0
-
-		// 分别存储各字段的匹配位置
-		questionMatches := []int{}
-		optionMatches := make(map[string][]int) // 为每个选项单独存储匹配位置
-		answerMatches := []int{}                // 答案不需要高亮，保持空数组
-
-		// 计算题目重合度（使用标准化后的文本进行匹配）
-		questionScore, _ := e.calculateOverlapScore(normalizedQuery, questionLower)
-		questionMatches = e.calculateMatchesForOriginalText(question, normalizedQuery)
-		if questionScore > maxScore {
-			maxScore = questionScore
-			matched = normalizedQuery
+	for _, target := range targets {
+		var scored []SearchResult
+		if req.ScoringMode == ScoringModeBM25 {
+			scored = e.scoreTargetBM25(target, normalizedQuery, queryTerms, req.Explain)
+		} else {
+			scored = e.scoreTargetHeuristic(target, normalizedQuery, queryTerms, cfg, req.Explain)
 		}
 
-		// 计算答案重合度
-		for _, ans := range answer.Answer {
-			normalizedAns := e.normalizeText(ans)
-			ansLower := strings.ToLower(normalizedAns)
-			ansScore, _ := e.calculateOverlapScore(normalizedQuery, ansLower)
-			ansMatches := e.calculateMatchesForOriginalText(ans, normalizedQuery)
-			if ansScore > maxScore {
-				maxScore = ansScore
-				matched = normalizedQuery
+		for _, result := range scored {
+			result.Bank = target.bank
+
+			// 根据准确度筛选
+			if filters.accepts(result.Score, cfg) {
+				log.Printf("搜索结果: 题库='%s', 题目='%s', 分数=%.2f, 题目匹配=%v, 选项匹配=%v, 答案匹配=%v",
+					result.Bank, result.Item.Question, result.Score, result.QuestionMatches, result.OptionMatches, result.AnswerMatches)
+				log.Printf("filters: %v", filters)
+				allPossibleMatches = append(allPossibleMatches, result)
 			}
-			// 合并所有答案的匹配位置
-			answerMatches = append(answerMatches, ansMatches...)
 		}
+	}
+
+	// 按匹配度排序，分数相同时保持题库中的原始顺序
+	sort.SliceStable(allPossibleMatches, func(i, j int) bool {
+		return allPossibleMatches[i].Score > allPossibleMatches[j].Score
+	})
 
-		// 计算选项重合度
-		for _, option := range answer.Options {
-			normalizedOption := e.normalizeText(option)
-			optionLower := strings.ToLower(normalizedOption)
-			optionScore, _ := e.calculateOverlapScore(normalizedQuery, optionLower)
-			optionMatchesForThis := e.calculateMatchesForOriginalText(option, normalizedQuery)
-			optionScore = optionScore * 0.8 // 选项权重稍低
-			if optionScore > maxScore {
-				maxScore = optionScore
-				matched = "选项匹配: " + normalizedQuery
+	return allPossibleMatches, nil
+}
+
+// scoreTargetHeuristic 使用启发式评分对题库评分，有索引时只对候选题目计算匹配度
+func (e *ExamService) scoreTargetHeuristic(target searchTarget, normalizedQuery string, queryTerms []string, cfg MatcherConfig, explain bool) []SearchResult {
+	candidates, ok := []int(nil), false
+	if target.index != nil {
+		candidates, ok = target.index.candidatesWithin(queryTerms, target.allowed)
+	}
+	if !ok {
+		candidates = make([]int, 0, len(target.answers))
+		for i := range target.answers {
+			if target.allowed == nil || target.allowed[i] {
+				candidates = append(candidates, i)
 			}
-			// 为每个选项单独存储匹配位置
-			optionMatches[option] = optionMatchesForThis
 		}
+	}
 
-		score = maxScore
+	results := make([]SearchResult, 0, len(candidates))
+	for _, i := range candidates {
+		results = append(results, e.scoreAnswer(target.answers[i], normalizedQuery, cfg, explain))
+	}
+	return results
+}
 
-		// 记录所有可能的匹配结果，包括低匹配度的
-		if score >= 0 {
-			// 限制分数不超过1.0
-			if score > 1.0 {
-				score = 1.0
-			}
+// scoreAnswer 计算单个题目与查询文本的匹配度，取题目、答案、选项中的最高分，
+// explain为true时附带得分最高字段的评分明细
+func (e *ExamService) scoreAnswer(answer AnswerItem, normalizedQuery string, cfg MatcherConfig, explain bool) SearchResult {
+	result := e.highlightAnswer(answer, normalizedQuery)
+	maxScore := 0.0
 
-			// 根据准确度筛选
-			shouldInclude := false
+	// 记录得分最高的字段，用于生成评分明细
+	bestField, bestText, bestLower := "", "", ""
 
-			// 如果所有过滤器都为false，显示所有结果
-			if !filters.High && !filters.Medium && !filters.Low {
-				shouldInclude = true
-			} else {
-				// 否则按过滤器筛选
-				if score >= 0.8 {
-					shouldInclude = filters.High
-				} else if score >= 0.5 {
-					shouldInclude = filters.Medium
-				} else {
-					shouldInclude = filters.Low
-				}
-			}
+	// 计算题目重合度（使用标准化后的文本进行匹配）
+	questionLower := strings.ToLower(e.normalizeText(answer.Question))
+	questionScore, _ := e.calculateOverlapScore(normalizedQuery, questionLower, cfg)
+	if questionScore > maxScore {
+		maxScore = questionScore
+		result.Matched = normalizedQuery
+		bestField, bestText, bestLower = MatchFieldQuestion, answer.Question, questionLower
+	}
 
-			if shouldInclude {
-				log.Printf("搜索结果: 题目='%s', 分数=%.2f, 题目匹配=%v, 选项匹配=%v, 答案匹配=%v",
-					answer.Question, score, questionMatches, optionMatches, answerMatches)
-				log.Printf("filters: %v", filters)
-				allPossibleMatches = append(allPossibleMatches, SearchResult{
-					Item:            answer,
-					Score:           score,
-					Matched:         matched,
-					QuestionMatches: questionMatches,
-					OptionMatches:   optionMatches,
-					AnswerMatches:   answerMatches,
-				})
-			}
+	// 计算答案重合度
+	for _, ans := range answer.Answer {
+		ansLower := strings.ToLower(e.normalizeText(ans))
+		ansScore, _ := e.calculateOverlapScore(normalizedQuery, ansLower, cfg)
+		if ansScore > maxScore {
+			maxScore = ansScore
+			result.Matched = normalizedQuery
+			bestField, bestText, bestLower = MatchFieldAnswer, ans, ansLower
 		}
 	}
 
-	// 按匹配度排序
-	sort.Slice(allPossibleMatches, func(i, j int) bool {
-		return allPossibleMatches[i].Score > allPossibleMatches[j].Score
-	})
+	// 计算选项重合度
+	for _, option := range answer.Options {
+		optionLower := strings.ToLower(e.normalizeText(option))
+		optionScore, _ := e.calculateOverlapScore(normalizedQuery, optionLower, cfg)
+		optionScore = optionScore * cfg.OptionWeight // 选项权重稍低
+		if optionScore > maxScore {
+			maxScore = optionScore
+			result.Matched = "选项匹配: " + normalizedQuery
+			bestField, bestText, bestLower = MatchFieldOption, option, optionLower
+		}
+	}
 
-	return allPossibleMatches, nil
+	// 限制分数不超过1.0
+	if maxScore > 1.0 {
+		maxScore = 1.0
+	}
+	result.Score = maxScore
+
+	if explain {
+		result.Explanation = e.explainOverlap(normalizedQuery, bestField, bestText, bestLower, cfg)
+		result.Explanation.Score = maxScore
+	}
+
+	return result
+}
+
+// highlightAnswer 计算题目、选项、答案中与查询文本匹配的位置，用于前端高亮
+func (e *ExamService) highlightAnswer(answer AnswerItem, normalizedQuery string) SearchResult {
+	// 分别存储各字段的匹配位置
+	questionMatches := e.calculateMatchesForOriginalText(answer.Question, normalizedQuery)
+	optionMatches := make(map[string][]int) // 为每个选项单独存储匹配位置
+	answerMatches := []int{}                // 答案不需要高亮，保持空数组
+
+	// 合并所有答案的匹配位置
+	for _, ans := range answer.Answer {
+		answerMatches = append(answerMatches, e.calculateMatchesForOriginalText(ans, normalizedQuery)...)
+	}
+
+	// 为每个选项单独存储匹配位置
+	for _, option := range answer.Options {
+		optionMatches[option] = e.calculateMatchesForOriginalText(option, normalizedQuery)
+	}
+
+	return SearchResult{
+		Item:            answer,
+		QuestionMatches: questionMatches,
+		OptionMatches:   optionMatches,
+		AnswerMatches:   answerMatches,
+	}
 }
 
 // calculateOverlapScore 计算重合度分数 - 使用智能匹配算法
-func (e *ExamService) calculateOverlapScore(query, text string) (float64, []int) {
+func (e *ExamService) calculateOverlapScore(query, text string, cfg MatcherConfig) (float64, []int) {
 	if query == "" || text == "" {
 		return 0.0, nil
 	}
@@ -930,10 +1107,10 @@ func (e *ExamService) calculateOverlapScore(query, text string) (float64, []int)
 		textLen := utf8.RuneCountInString(text)
 		matchRate := float64(charLen) / float64(textLen)
 
-		// 包含匹配给予90-95%的匹配度，但不超过95%
-		score := 0.9 + matchRate*0.05
-		if score > 0.95 {
-			score = 0.95
+		// 包含匹配的匹配度在ContainmentMin和ContainmentMax之间（默认90-95%）
+		score := cfg.ContainmentMin + matchRate*(cfg.ContainmentMax-cfg.ContainmentMin)
+		if score > cfg.ContainmentMax {
+			score = cfg.ContainmentMax
 		}
 
 		return score, matches
@@ -948,36 +1125,32 @@ func (e *ExamService) calculateOverlapScore(query, text string) (float64, []int)
 			matches = append(matches, i)
 		}
 
-		// 完全匹配，给予95%的匹配度
-		return 0.95, matches
+		// 完全匹配，给予ContainmentMax的匹配度（默认95%）
+		return cfg.ContainmentMax, matches
 	}
 
 	// 使用智能匹配算法
-	return e.calculateSmartSimilarity(query, text)
+	return e.calculateSmartSimilarity(query, text, cfg)
 }
 
 // calculateSmartSimilarity 智能相似度计算
-func (e *ExamService) calculateSmartSimilarity(query, text string) (float64, []int) {
+func (e *ExamService) calculateSmartSimilarity(query, text string, cfg MatcherConfig) (float64, []int) {
 	// 1. 首先检查是否有任何共同的关键词
 	commonWords := e.findCommonWords(query, text)
 	if len(commonWords) == 0 {
 		// 没有共同关键词，尝试使用编辑距离作为备选方案
-		editDistance := e.calculateEditDistance([]rune(query), []rune(text))
-		maxPossibleDistance := max(len(query), len(text))
-		editSimilarity := 1.0 - float64(editDistance)/float64(maxPossibleDistance)
+		editSimilarity := e.calculateEditSimilarity(query, text)
 
 		// 降低阈值，允许更多可能的匹配
-		if editSimilarity > 0.3 {
+		if editSimilarity > cfg.EditSimilarityCutoff {
 			matches := e.calculateSimpleMatches([]rune(query), []rune(text))
-			return editSimilarity * 0.6, matches // 降低权重
+			return editSimilarity * cfg.EditFallbackWeight, matches // 降低权重
 		}
 		return 0, nil
 	}
 
 	// 2. 计算编辑距离相似度
-	editDistance := e.calculateEditDistance([]rune(query), []rune(text))
-	maxPossibleDistance := max(len(query), len(text))
-	editSimilarity := 1.0 - float64(editDistance)/float64(maxPossibleDistance)
+	editSimilarity := e.calculateEditSimilarity(query, text)
 
 	// 3. 计算关键词匹配度
 	keywordSimilarity := e.calculateKeywordSimilarity(query, text)
@@ -985,11 +1158,11 @@ func (e *ExamService) calculateSmartSimilarity(query, text string) (float64, []i
 	// 4. 计算字符匹配度
 	charSimilarity := e.calculateCharSimilarity(query, text)
 
-	// 5. 综合评分：编辑距离20%，关键词匹配50%，字符匹配30%
-	similarity := editSimilarity*0.2 + keywordSimilarity*0.5 + charSimilarity*0.3
+	// 5. 综合评分：默认编辑距离20%，关键词匹配50%，字符匹配30%
+	similarity := editSimilarity*cfg.EditWeight + keywordSimilarity*cfg.KeywordWeight + charSimilarity*cfg.CharWeight
 
 	// 6. 调整阈值，允许更多可能的匹配
-	if similarity < 0.1 {
+	if similarity < cfg.MinSimilarity {
 		return 0.0, nil
 	}
 
@@ -999,6 +1172,13 @@ func (e *ExamService) calculateSmartSimilarity(query, text string) (float64, []i
 	return similarity, matches
 }
 
+// calculateEditSimilarity 根据编辑距离计算相似度
+func (e *ExamService) calculateEditSimilarity(query, text string) float64 {
+	editDistance := e.calculateEditDistance([]rune(query), []rune(text))
+	maxPossibleDistance := max(len(query), len(text))
+	return 1.0 - editDistance/float64(maxPossibleDistance)
+}
+
 // calculateCharSimilarity 计算字符级别的相似度
 func (e *ExamService) calculateCharSimilarity(query, text string) float64 {
 	if query == "" || text == "" {
@@ -1028,17 +1208,39 @@ func (e *ExamService) calculateCharSimilarity(query, text string) float64 {
 	for char, queryCount := range queryCharSet {
 		if textCount, exists := textCharSet[char]; exists {
 			// 取两个文本中该字符出现次数的最小值
-			commonChars += min(queryCount, textCount)
+			common := min(queryCount, textCount)
+			commonChars += common
+			queryCharSet[char] -= common
+			textCharSet[char] -= common
 		}
 	}
 
+	// 剩余未匹配的字符按形近字折算为部分匹配，按查询文本顺序处理以保证结果稳定
+	table := confusions.Load()
+	similarChars := 0.0
+	for _, char := range queryChars {
+		queryCount := queryCharSet[char]
+		for _, partner := range table.partners[char] {
+			if queryCount == 0 {
+				break
+			}
+			if textCount := textCharSet[partner.char]; textCount > 0 {
+				matched := min(queryCount, textCount)
+				similarChars += float64(matched) * (1 - partner.cost)
+				queryCount -= matched
+				textCharSet[partner.char] -= matched
+			}
+		}
+		queryCharSet[char] = queryCount
+	}
+
 	// 计算相似度：共同字符数 / 总字符数
 	totalChars := len(queryChars) + len(textChars)
 	if totalChars == 0 {
 		return 0.0
 	}
 
-	similarity := float64(commonChars*2) / float64(totalChars)
+	similarity := (float64(commonChars) + similarChars) * 2 / float64(totalChars)
 	return similarity
 }
 
@@ -1125,23 +1327,24 @@ func (e *ExamService) calculateKeywordSimilarity(s1, s2 string) float64 {
 	return similarity
 }
 
-// calculateEditDistance 计算编辑距离
-func (e *ExamService) calculateEditDistance(query, text []rune) int {
+// calculateEditDistance 计算编辑距离，形近字之间的替换代价低于普通字符
+func (e *ExamService) calculateEditDistance(query, text []rune) float64 {
 	lenQuery := len(query)
 	lenText := len(text)
+	table := confusions.Load()
 
 	// 创建DP表
-	dp := make([][]int, lenQuery+1)
+	dp := make([][]float64, lenQuery+1)
 	for i := range dp {
-		dp[i] = make([]int, lenText+1)
+		dp[i] = make([]float64, lenText+1)
 	}
 
 	// 初始化第一行和第一列
 	for i := 0; i <= lenQuery; i++ {
-		dp[i][0] = i
+		dp[i][0] = float64(i)
 	}
 	for j := 0; j <= lenText; j++ {
-		dp[0][j] = j
+		dp[0][j] = float64(j)
 	}
 
 	// 填充DP表
@@ -1150,7 +1353,8 @@ func (e *ExamService) calculateEditDistance(query, text []rune) int {
 			if query[i-1] == text[j-1] {
 				dp[i][j] = dp[i-1][j-1]
 			} else {
-				dp[i][j] = min(dp[i-1][j], min(dp[i][j-1], dp[i-1][j-1])) + 1
+				substitution := dp[i-1][j-1] + table.substitutionCost(query[i-1], text[j-1])
+				dp[i][j] = math.Min(math.Min(dp[i-1][j], dp[i][j-1])+1, substitution)
 			}
 		}
 	}
@@ -1304,7 +1508,7 @@ func (e *ExamService) calculateMatchesForOriginalText(originalText, query string
 		charLen := utf8.RuneCountInString(queryLower)
 
 		// 将标准化文本的位置映射回原始文本的位置
-		originalMatches := e.mapNormalizedPositionsToOriginal(originalText, normalizedOriginal, charStart, charLen)
+		originalMatches := e.mapNormalizedPositionsToOriginal(originalText, charStart, charLen)
 		matches = originalMatches
 		return matches
 	}
@@ -1313,7 +1517,7 @@ func (e *ExamService) calculateMatchesForOriginalText(originalText, query string
 	if strings.Contains(queryLower, originalLower) {
 		// 目标文本完全匹配，返回所有位置
 		charLen := utf8.RuneCountInString(originalLower)
-		originalMatches := e.mapNormalizedPositionsToOriginal(originalText, normalizedOriginal, 0, charLen)
+		originalMatches := e.mapNormalizedPositionsToOriginal(originalText, 0, charLen)
 		matches = originalMatches
 		return matches
 	}
@@ -1328,7 +1532,7 @@ func (e *ExamService) calculateMatchesForOriginalText(originalText, query string
 			charLen := utf8.RuneCountInString(commonSubstr)
 
 			// 将标准化文本的位置映射回原始文本的位置
-			originalMatches := e.mapNormalizedPositionsToOriginal(originalText, normalizedOriginal, charStart, charLen)
+			originalMatches := e.mapNormalizedPositionsToOriginal(originalText, charStart, charLen)
 			matches = originalMatches
 		}
 	}
@@ -1337,29 +1541,13 @@ func (e *ExamService) calculateMatchesForOriginalText(originalText, query string
 }
 
 // mapNormalizedPositionsToOriginal 将标准化文本的位置映射回原始文本的位置
-func (e *ExamService) mapNormalizedPositionsToOriginal(originalText, normalizedText string, normalizedStart, normalizedLen int) []int {
+func (e *ExamService) mapNormalizedPositionsToOriginal(originalText string, normalizedStart, normalizedLen int) []int {
 	matches := []int{}
 
-	// 将原始文本和标准化文本都转换为字符数组
 	originalChars := []rune(originalText)
-	normalizedChars := []rune(normalizedText)
-
-	// 创建标准化文本到原始文本的位置映射
-	normalizedToOriginal := make([]int, len(normalizedChars))
-
-	// 构建位置映射关系
-	normalizedPos := 0
-	for originalPos, char := range originalChars {
-		// 检查这个字符在标准化后是否保留
-		normalizedChar := e.normalizeChar(char)
-		if normalizedChar != 0 {
-			if normalizedPos < len(normalizedToOriginal) {
-				normalizedToOriginal[normalizedPos] = originalPos
-				normalizedPos++
-			}
-		}
-		// 如果字符被移除，跳过
-	}
+
+	// 标准化文本到原始文本的位置映射，与normalizeText使用同一套规则生成
+	_, normalizedToOriginal := normalizeWithPositions(originalText)
 
 	// 将标准化文本的匹配位置映射回原始文本位置
 	for i := normalizedStart; i < normalizedStart+normalizedLen; i++ {
@@ -1374,26 +1562,6 @@ func (e *ExamService) mapNormalizedPositionsToOriginal(originalText, normalizedT
 	return matches
 }
 
-// normalizeChar 标准化单个字符，返回标准化后的字符，如果字符被移除则返回0
-func (e *ExamService) normalizeChar(char rune) rune {
-	// 移除标点符号和特殊字符，与normalizeText函数保持一致
-	specialChars := map[rune]bool{
-		'(': true, ')': true, '[': true, ']': true, '{': true, '}': true,
-		'（': true, '）': true, '【': true, '】': true, '《': true, '》': true,
-		'"': true, '\'': true, '`': true, '~': true, '!': true, '@': true,
-		'#': true, '$': true, '%': true, '^': true, '&': true, '*': true,
-		'+': true, '=': true, '|': true, '\\': true, '/': true, '?': true,
-		'<': true, '>': true, ',': true, '.': true, ';': true, ':': true,
-		'、': true, '，': true, '。': true, '；': true, '：': true, '！': true,
-		'？': true, '…': true, '—': true, '－': true, '·': true, '　': true,
-	}
-
-	if specialChars[char] {
-		return 0 // 返回0表示字符被移除
-	}
-	return char
-}
-
 // NextQuestion 下一题功能
 func (e *ExamService) NextQuestion(area ScreenshotArea, config OCRConfig) (string, error) {
 	// 1. 重新截图
@@ -1487,27 +1655,39 @@ func (e *ExamService) ShowWindow() error {
 	return nil
 }
 
-// 全局变量存储答案数据
-var globalAnswers []AnswerItem
-
-// SetGlobalAnswers 设置全局答案数据
-func (e *ExamService) SetGlobalAnswers(answers []AnswerItem) {
-	globalAnswers = answers
+// SetGlobalAnswers 设置当前激活题库的答案数据，先写入磁盘再更新内存
+func (e *ExamService) SetGlobalAnswers(answers []AnswerItem) error {
+	return e.SetBankAnswers("", answers)
 }
 
-// GetGlobalAnswers 获取全局答案数据
+// GetGlobalAnswers 获取当前激活题库的答案数据
 func (e *ExamService) GetGlobalAnswers() []AnswerItem {
-	return globalAnswers
+	answers, _ := e.GetBankAnswers("")
+	return answers
 }
 
 // SearchRequest HTTP搜索请求结构
 type SearchRequest struct {
-	Query   string        `json:"query"`
-	Filters SearchFilters `json:"filters"`
+	Query    string        `json:"query"`
+	Filters  SearchFilters `json:"filters"`
+	Banks    []string      `json:"banks,omitempty"`    // 要搜索的题库，为空时搜索当前激活的题库
+	AllBanks bool          `json:"allBanks,omitempty"` // 搜索全部题库
+
+	// 评分模式：heuristic（默认）或 bm25
+	ScoringMode string `json:"scoringMode,omitempty"`
+
+	// 是否在每个结果中附带评分明细，用于排查排序问题
+	Explain bool `json:"explain,omitempty"`
 }
 
 type SearchFilters struct {
 	AccuracyFilters AccuracyFilters `json:"accuracyFilters"`
+
+	// 按题目属性限定搜索范围，同一属性中满足任意一个即可，不同属性需同时满足，为空时不限
+	Chapters   []string `json:"chapters,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Types      []string `json:"types,omitempty"` // 按标准题型比较，可使用题型别名，如"选择题"
 }
 
 // SearchResponse HTTP搜索响应结构
@@ -1520,21 +1700,30 @@ type SearchResponse struct {
 // ParseCSVRequest HTTP CSV解析请求结构
 type ParseCSVRequest struct {
 	FilePath        string `json:"filePath"`
-	Encoding        string `json:"encoding"`
-	OptionSeparator string `json:"optionSeparator"`
-	AnswerSeparator string `json:"answerSeparator"`
+	Encoding        string `json:"encoding"`            // 文件编码，auto或空时自动检测
+	Delimiter       string `json:"delimiter,omitempty"` // 字段分隔符，auto或空时自动检测
+	OptionSeparator string `json:"optionSeparator"`     // 选项分隔符，auto时根据选项内容推断
+	AnswerSeparator string `json:"answerSeparator"`     // 答案分隔符，auto时与选项分隔符相同
+	Profile         string `json:"profile,omitempty"`   // 列映射方案名称
+	Strict          bool   `json:"strict,omitempty"`    // 严格模式：任意一行有问题即整体失败，默认跳过有问题的行
 }
 
 // ParseCSVResponse HTTP CSV解析响应结构
 type ParseCSVResponse struct {
-	Success bool         `json:"success"`
-	Message string       `json:"message,omitempty"`
-	Results []AnswerItem `json:"results,omitempty"`
+	Success  bool         `json:"success"`
+	Message  string       `json:"message,omitempty"`
+	Encoding string       `json:"encoding,omitempty"` // 实际使用的文件编码，自动检测时为检测结果
+	Results  []AnswerItem `json:"results,omitempty"`
+
+	Separators  *DetectedSeparators `json:"separators,omitempty"`  // 实际使用的分隔符，供用户确认
+	Report      *ImportReport       `json:"report,omitempty"`      // 逐行导入报告
+	HeaderError *HeaderError        `json:"headerError,omitempty"` // 标题行缺少必需字段时的详细信息，供前端建立列映射
 }
 
 // SetGlobalAnswersRequest HTTP设置全局答案请求结构
 type SetGlobalAnswersRequest struct {
 	Answers []AnswerItem `json:"answers"`
+	Bank    string       `json:"bank,omitempty"` // 目标题库，为空时写入当前激活的题库
 }
 
 // SetGlobalAnswersResponse HTTP设置全局答案响应结构
@@ -1579,12 +1768,15 @@ func handleParseCSV(w http.ResponseWriter, r *http.Request) {
 	// 创建ExamService实例
 	examService := &ExamService{}
 
-	// 调用ParseCSVFile方法
-	results, err := examService.ParseCSVFile(req.FilePath, req.Encoding, req.OptionSeparator, req.AnswerSeparator)
+	// 解析CSV文件，同时生成导入报告
+	result, err := examService.parseCSVFile(req)
 	if err != nil {
 		response := ParseCSVResponse{
-			Success: false,
-			Message: "CSV解析失败: " + err.Error(),
+			Success:     false,
+			Message:     "CSV解析失败: " + err.Error(),
+			Encoding:    result.Encoding,
+			Report:      &result.Report,
+			HeaderError: headerErrorOf(err),
 		}
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
@@ -1593,8 +1785,12 @@ func handleParseCSV(w http.ResponseWriter, r *http.Request) {
 
 	// 返回解析结果
 	response := ParseCSVResponse{
-		Success: true,
-		Results: results,
+		Success:    true,
+		Message:    result.Report.Summary(),
+		Encoding:   result.Encoding,
+		Separators: &result.Separators,
+		Results:    result.Items,
+		Report:     &result.Report,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -1630,9 +1826,9 @@ func handleSearch(w http.ResponseWriter, r *http.Request) {
 	// 创建ExamService实例
 	examService := &ExamService{}
 
-	// 使用全局答案数据进行搜索
+	// 在请求指定的题库中进行搜索
 	log.Printf("req %v", req)
-	results, err := examService.SearchAnswers(globalAnswers, req.Query, req.Filters.AccuracyFilters)
+	results, err := examService.Search(req)
 	if err != nil {
 		response := SearchResponse{
 			Success: false,
@@ -1682,8 +1878,16 @@ func handleSetGlobalAnswers(w http.ResponseWriter, r *http.Request) {
 	// 创建ExamService实例
 	examService := &ExamService{}
 
-	// 调用SetGlobalAnswers方法
-	examService.SetGlobalAnswers(req.Answers)
+	// 调用SetBankAnswers方法
+	if err := examService.SetBankAnswers(req.Bank, req.Answers); err != nil {
+		response := SetGlobalAnswersResponse{
+			Success: false,
+			Message: "设置全局答案失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 
 	// 返回设置结果
 	response := SetGlobalAnswersResponse{
@@ -1717,8 +1921,17 @@ func handleGetGlobalAnswers(w http.ResponseWriter, r *http.Request) {
 	// 创建ExamService实例
 	examService := &ExamService{}
 
-	// 调用GetGlobalAnswers方法
-	answers := examService.GetGlobalAnswers()
+	// 调用GetBankAnswers方法，bank参数为空时返回当前激活的题库
+	answers, err := examService.GetBankAnswers(r.URL.Query().Get("bank"))
+	if err != nil {
+		response := GetGlobalAnswersResponse{
+			Success: false,
+			Message: "获取全局答案失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
 
 	// 返回获取结果
 	response := GetGlobalAnswersResponse{
@@ -0,0 +1,626 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// bankHistoryDirName 题库历史版本在数据目录下的子目录名
+const bankHistoryDirName = "history"
+
+// bankVersionsFileName 每个题库历史目录中的版本列表文件名
+const bankVersionsFileName = "versions.json"
+
+// maxBankVersions 每个题库至少保留的历史版本数量，超出时删除最早的版本。
+// 删除以完整快照为界，实际保留的版本可能多出不到bankSnapshotInterval个
+const maxBankVersions = 20
+
+// bankSnapshotInterval 每隔多少个版本保存一次完整快照，其余版本只保存与上一版本的差异
+const bankSnapshotInterval = 10
+
+// 题库变更的类型
+const (
+	BankActionInitial  = "initial"  // 开始记录前的题库
+	BankActionImport   = "import"   // 导入并替换全部题目
+	BankActionMerge    = "merge"    // 合并题库
+	BankActionEdit     = "edit"     // 新增、修改题目
+	BankActionDelete   = "delete"   // 删除题目
+	BankActionRollback = "rollback" // 回滚到历史版本
+)
+
+// 题目差异的类型
+const (
+	QuestionAdded    = "added"
+	QuestionRemoved  = "removed"
+	QuestionModified = "modified"
+)
+
+// bankChange 一次题库变更的说明
+type bankChange struct {
+	action  string
+	summary string
+}
+
+// BankVersion 题库的一个历史版本，记录变更后的题目
+type BankVersion struct {
+	Version int       `json:"version"` // 版本号，从1开始递增
+	Time    time.Time `json:"time"`
+	Action  string    `json:"action"`
+	Summary string    `json:"summary"`
+	Count   int       `json:"count"`           // 该版本的题目数量
+	Delta   bool      `json:"delta,omitempty"` // 只保存了与上一版本的差异，否则为完整快照
+}
+
+// bankDelta 一个版本与上一版本的差异
+type bankDelta struct {
+	Upserts []AnswerItem `json:"upserts,omitempty"` // 新增和修改的题目
+	Removed []string     `json:"removed,omitempty"` // 删除的题目ID
+	Order   []string     `json:"order,omitempty"`   // 全部题目ID的顺序，按差异还原后顺序不对时才记录
+}
+
+// QuestionChange 两个版本之间一道题的差异
+type QuestionChange struct {
+	Kind   string      `json:"kind"` // added、removed或modified
+	ID     string      `json:"id"`
+	Before *AnswerItem `json:"before,omitempty"`
+	After  *AnswerItem `json:"after,omitempty"`
+	Fields []string    `json:"fields,omitempty"` // 修改的字段，如question、options、answer
+}
+
+// BankDiff 两个版本之间的逐题差异
+type BankDiff struct {
+	Bank     string           `json:"bank"`
+	From     int              `json:"from"`
+	To       int              `json:"to"` // 为0时表示题库当前的题目
+	Added    int              `json:"added"`
+	Removed  int              `json:"removed"`
+	Modified int              `json:"modified"`
+	Changes  []QuestionChange `json:"changes"`
+}
+
+// bankHistoryDir 返回题库的历史版本目录，目录名由题库名称的哈希得到，避免名称中的特殊字符
+func bankHistoryDir(name string) (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(name))
+	return filepath.Join(dir, bankHistoryDirName, hex.EncodeToString(sum[:8])), nil
+}
+
+// readBankVersions 读取题库的版本列表，没有历史时返回空列表
+func readBankVersions(dir string) ([]BankVersion, error) {
+	data, err := os.ReadFile(filepath.Join(dir, bankVersionsFileName))
+	if os.IsNotExist(err) {
+		return []BankVersion{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取历史版本失败: %v", err)
+	}
+
+	var versions []BankVersion
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, fmt.Errorf("解析历史版本失败: %v", err)
+	}
+	return versions, nil
+}
+
+// bankSnapshotPath 返回某个版本题目快照或差异的文件路径
+func bankSnapshotPath(dir string, version int) string {
+	return filepath.Join(dir, strconv.Itoa(version)+".json")
+}
+
+// newBankDelta 计算after相对before的差异
+func newBankDelta(before []AnswerItem, after []AnswerItem) bankDelta {
+	delta := bankDelta{}
+	for _, change := range diffAnswers(before, after).Changes {
+		if change.Kind == QuestionRemoved {
+			delta.Removed = append(delta.Removed, change.ID)
+		} else {
+			delta.Upserts = append(delta.Upserts, *change.After)
+		}
+	}
+	if restored := delta.apply(before); !sameQuestionOrder(restored, after) {
+		delta.Order = make([]string, len(after))
+		for i, item := range after {
+			delta.Order[i] = item.ID
+		}
+	}
+	return delta
+}
+
+// apply 在上一版本的题目上应用差异：删除的题目去掉，修改的题目原位替换，新增的题目按顺序加在最后，
+// 记录了顺序时再按顺序排列
+func (d bankDelta) apply(before []AnswerItem) []AnswerItem {
+	removed := make(map[string]bool, len(d.Removed))
+	for _, id := range d.Removed {
+		removed[id] = true
+	}
+	upserts := make(map[string]AnswerItem, len(d.Upserts))
+	for _, item := range d.Upserts {
+		upserts[item.ID] = item
+	}
+
+	answers := make([]AnswerItem, 0, len(before)+len(d.Upserts))
+	for _, item := range before {
+		if removed[item.ID] {
+			continue
+		}
+		if updated, ok := upserts[item.ID]; ok {
+			item = updated
+			delete(upserts, item.ID)
+		}
+		answers = append(answers, item)
+	}
+	for _, item := range d.Upserts {
+		if _, ok := upserts[item.ID]; ok {
+			answers = append(answers, item)
+		}
+	}
+
+	if len(d.Order) == 0 {
+		return answers
+	}
+	byID := make(map[string]AnswerItem, len(answers))
+	for _, item := range answers {
+		byID[item.ID] = item
+	}
+	ordered := make([]AnswerItem, 0, len(d.Order))
+	for _, id := range d.Order {
+		if item, ok := byID[id]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
+
+// sameQuestionOrder 判断两组题目的ID顺序是否相同
+func sameQuestionOrder(a []AnswerItem, b []AnswerItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+// appendBankVersion 保存一个版本并加入版本列表。previous为上一版本的题目，为nil时保存完整快照；
+// 距上次完整快照已有bankSnapshotInterval个版本或差异超过题目的一半时也保存完整快照，否则只保存差异
+func appendBankVersion(dir string, versions []BankVersion, version BankVersion, previous []AnswerItem, answers []AnswerItem) ([]BankVersion, error) {
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	} else {
+		version.Version = 1
+	}
+	version.Count = len(answers)
+
+	var content interface{} = answers
+	if previous != nil && versionsSinceSnapshot(versions) < bankSnapshotInterval {
+		if delta := newBankDelta(previous, answers); 2*(len(delta.Upserts)+len(delta.Removed)) < len(answers) {
+			content, version.Delta = delta, true
+		}
+	}
+	data, err := json.Marshal(content)
+	if err != nil {
+		return versions, fmt.Errorf("编码题目快照失败: %v", err)
+	}
+	if err := writeFileAtomic(bankSnapshotPath(dir, version.Version), data); err != nil {
+		return versions, err
+	}
+
+	versions = append(versions, version)
+	return pruneBankVersions(dir, versions), nil
+}
+
+// versionsSinceSnapshot 最后一个完整快照之后（含该快照）的版本数量，没有快照时视为已达到间隔
+func versionsSinceSnapshot(versions []BankVersion) int {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].Delta {
+			return len(versions) - i
+		}
+	}
+	return bankSnapshotInterval
+}
+
+// pruneBankVersions 超出保留数量时删除最早的版本。差异版本依赖之前的版本还原，
+// 因此只删除到下一个完整快照之前，保证最早的版本总是完整快照
+func pruneBankVersions(dir string, versions []BankVersion) []BankVersion {
+	for len(versions) > maxBankVersions {
+		next := -1
+		for i := 1; i < len(versions); i++ {
+			if !versions[i].Delta {
+				next = i
+				break
+			}
+		}
+		if next < 0 || len(versions)-next < maxBankVersions {
+			break
+		}
+		for _, version := range versions[:next] {
+			os.Remove(bankSnapshotPath(dir, version.Version))
+		}
+		versions = versions[next:]
+	}
+	return versions
+}
+
+// recordBankVersion 将题库的一次变更记录为新版本，调用方需持有bankMu。
+// 题库还没有历史时，先把变更前的题目记录为初始版本，保证第一次变更也可以回滚。
+// 差异相对变更前的题目计算，最后一个版本的题目数量与之不符（之前有记录失败）时保存完整快照
+func recordBankVersion(previous *QuestionBank, current *QuestionBank, change bankChange) error {
+	dir, err := bankHistoryDir(current.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("创建历史版本目录失败: %v", err)
+	}
+
+	versions, err := readBankVersions(dir)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 && previous != nil && len(previous.Answers) > 0 {
+		initial := BankVersion{Time: previous.UpdatedAt, Action: BankActionInitial, Summary: "开始记录历史前的题库"}
+		if versions, err = appendBankVersion(dir, versions, initial, nil, previous.Answers); err != nil {
+			return err
+		}
+	}
+
+	var base []AnswerItem
+	if previous != nil && len(versions) > 0 && versions[len(versions)-1].Count == len(previous.Answers) {
+		base = previous.Answers
+	}
+	version := BankVersion{Time: current.UpdatedAt, Action: change.action, Summary: change.summary}
+	if versions, err = appendBankVersion(dir, versions, version, base, current.Answers); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return fmt.Errorf("编码历史版本失败: %v", err)
+	}
+	return writeFileAtomic(filepath.Join(dir, bankVersionsFileName), data)
+}
+
+// logBankVersion 记录题库变更，失败时只写日志：题库本身已经保存成功，不应因历史记录失败而报错
+func logBankVersion(previous *QuestionBank, current *QuestionBank, change bankChange) {
+	if err := recordBankVersion(previous, current, change); err != nil {
+		log.Printf("记录题库历史版本失败: 题库='%s', %v", current.Name, err)
+	}
+}
+
+// renameBankHistory 题库重命名时移动历史版本目录，调用方需持有bankMu
+func renameBankHistory(oldName string, newName string) {
+	oldDir, err := bankHistoryDir(oldName)
+	if err != nil {
+		return
+	}
+	newDir, err := bankHistoryDir(newName)
+	if err != nil {
+		return
+	}
+	if err := os.Rename(oldDir, newDir); err != nil && !os.IsNotExist(err) {
+		log.Printf("移动题库历史版本失败: 题库='%s', %v", oldName, err)
+	}
+}
+
+// removeBankHistory 删除题库时一并删除其历史版本，调用方需持有bankMu
+func removeBankHistory(name string) {
+	dir, err := bankHistoryDir(name)
+	if err != nil {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.Printf("删除题库历史版本失败: 题库='%s', %v", name, err)
+	}
+}
+
+// loadBankVersion 读取题库某个版本的题目：从该版本之前最近的完整快照开始依次应用差异
+func loadBankVersion(name string, version int) ([]AnswerItem, error) {
+	dir, err := bankHistoryDir(name)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := readBankVersions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	target := slices.IndexFunc(versions, func(v BankVersion) bool { return v.Version == version })
+	if target < 0 {
+		return nil, fmt.Errorf("版本不存在: %d", version)
+	}
+	start := target
+	for start > 0 && versions[start].Delta {
+		start--
+	}
+
+	var answers []AnswerItem
+	for _, v := range versions[start : target+1] {
+		data, err := os.ReadFile(bankSnapshotPath(dir, v.Version))
+		if err != nil {
+			return nil, fmt.Errorf("读取版本失败: %v", err)
+		}
+		if !v.Delta {
+			answers = nil
+			if err := json.Unmarshal(data, &answers); err != nil {
+				return nil, fmt.Errorf("解析版本失败: %v", err)
+			}
+			continue
+		}
+		var delta bankDelta
+		if err := json.Unmarshal(data, &delta); err != nil {
+			return nil, fmt.Errorf("解析版本失败: %v", err)
+		}
+		answers = delta.apply(answers)
+	}
+	return answers, nil
+}
+
+// briefQuestion 截取题目开头用于变更说明
+func briefQuestion(question string) string {
+	const maxRunes = 30
+	if utf8.RuneCountInString(question) <= maxRunes {
+		return question
+	}
+	return string([]rune(question)[:maxRunes]) + "…"
+}
+
+// ListBankVersions 列出题库的历史版本，按版本号从旧到新排列，name为空时使用当前激活的题库
+func (e *ExamService) ListBankVersions(name string) ([]BankVersion, error) {
+	bank, err := findBank(name)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := bankHistoryDir(bank.Name)
+	if err != nil {
+		return nil, err
+	}
+	return readBankVersions(dir)
+}
+
+// DiffBankVersions 逐题比较题库的两个版本，按题目ID对应。to为0时与题库当前的题目比较
+func (e *ExamService) DiffBankVersions(name string, from int, to int) (BankDiff, error) {
+	bank, err := findBank(name)
+	if err != nil {
+		return BankDiff{}, err
+	}
+
+	before, err := loadBankVersion(bank.Name, from)
+	if err != nil {
+		return BankDiff{}, err
+	}
+	after := bank.Answers
+	if to != 0 {
+		if after, err = loadBankVersion(bank.Name, to); err != nil {
+			return BankDiff{}, err
+		}
+	}
+
+	diff := diffAnswers(before, after)
+	diff.Bank, diff.From, diff.To = bank.Name, from, to
+	return diff, nil
+}
+
+// diffAnswers 按题目ID比较两组题目，新增和修改的按after的顺序排列，删除的排在最后
+func diffAnswers(before []AnswerItem, after []AnswerItem) BankDiff {
+	diff := BankDiff{Changes: []QuestionChange{}}
+
+	previous := make(map[string]int, len(before))
+	for i, item := range before {
+		previous[item.ID] = i
+	}
+
+	seen := map[string]bool{}
+	for i := range after {
+		item := &after[i]
+		seen[item.ID] = true
+		j, ok := previous[item.ID]
+		if !ok {
+			diff.Added++
+			diff.Changes = append(diff.Changes, QuestionChange{Kind: QuestionAdded, ID: item.ID, After: item})
+			continue
+		}
+		if fields := changedFields(before[j], *item); len(fields) > 0 {
+			diff.Modified++
+			diff.Changes = append(diff.Changes, QuestionChange{Kind: QuestionModified, ID: item.ID, Before: &before[j], After: item, Fields: fields})
+		}
+	}
+	for i := range before {
+		if item := &before[i]; !seen[item.ID] {
+			diff.Removed++
+			diff.Changes = append(diff.Changes, QuestionChange{Kind: QuestionRemoved, ID: item.ID, Before: item})
+		}
+	}
+	return diff
+}
+
+// changedFields 返回两道题内容不同的字段，字段名与JSON中的名称一致
+func changedFields(a AnswerItem, b AnswerItem) []string {
+	fields := []string{}
+	if a.Type != b.Type {
+		fields = append(fields, "type")
+	}
+	if a.Question != b.Question {
+		fields = append(fields, "question")
+	}
+	if !slices.Equal(a.Options, b.Options) {
+		fields = append(fields, "options")
+	}
+	if !slices.Equal(a.Answer, b.Answer) {
+		fields = append(fields, "answer")
+	}
+	if a.Explanation != b.Explanation {
+		fields = append(fields, "explanation")
+	}
+	if a.Category != b.Category {
+		fields = append(fields, "category")
+	}
+	if a.Difficulty != b.Difficulty {
+		fields = append(fields, "difficulty")
+	}
+	if a.Chapter != b.Chapter {
+		fields = append(fields, "chapter")
+	}
+	if !slices.Equal(a.Tags, b.Tags) {
+		fields = append(fields, "tags")
+	}
+	if a.Source != b.Source {
+		fields = append(fields, "source")
+	}
+	return fields
+}
+
+// RollbackBank 将题库恢复为某个历史版本的题目。回滚本身也记录为新版本，可以再次回滚
+func (e *ExamService) RollbackBank(name string, version int) error {
+	bank, err := findBank(name)
+	if err != nil {
+		return err
+	}
+	answers, err := loadBankVersion(bank.Name, version)
+	if err != nil {
+		return err
+	}
+	return storeBankAnswers(bank.Name, answers, nil, bankChange{
+		action:  BankActionRollback,
+		summary: fmt.Sprintf("回滚到版本 %d", version),
+	})
+}
+
+// BankVersionRequest HTTP题库历史版本请求结构
+type BankVersionRequest struct {
+	Bank    string `json:"bank,omitempty"`    // 题库名称，为空时使用当前激活的题库
+	From    int    `json:"from,omitempty"`    // 比较的起始版本
+	To      int    `json:"to,omitempty"`      // 比较的目标版本，为0时与当前题目比较
+	Version int    `json:"version,omitempty"` // 回滚的目标版本
+}
+
+// BankVersionResponse HTTP题库历史版本响应结构
+type BankVersionResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message,omitempty"`
+	Versions []BankVersion `json:"versions,omitempty"`
+	Diff     *BankDiff     `json:"diff,omitempty"`
+}
+
+// handleListBankVersions 处理HTTP题库历史版本列表请求，参数为bank
+func handleListBankVersions(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	versions, err := examService.ListBankVersions(r.URL.Query().Get("bank"))
+	if err != nil {
+		response := BankVersionResponse{
+			Success: false,
+			Message: "获取历史版本失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := BankVersionResponse{
+		Success:  true,
+		Versions: versions,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleDiffBankVersions 处理HTTP比较题库版本请求
+func handleDiffBankVersions(w http.ResponseWriter, r *http.Request) {
+	serveBankVersion(w, r, "比较版本", func(e *ExamService, req BankVersionRequest) (BankVersionResponse, error) {
+		diff, err := e.DiffBankVersions(req.Bank, req.From, req.To)
+		return BankVersionResponse{Diff: &diff}, err
+	})
+}
+
+// handleRollbackBank 处理HTTP回滚题库请求，返回回滚后的版本列表
+func handleRollbackBank(w http.ResponseWriter, r *http.Request) {
+	serveBankVersion(w, r, "回滚题库", func(e *ExamService, req BankVersionRequest) (BankVersionResponse, error) {
+		if err := e.RollbackBank(req.Bank, req.Version); err != nil {
+			return BankVersionResponse{}, err
+		}
+		versions, err := e.ListBankVersions(req.Bank)
+		return BankVersionResponse{Versions: versions}, err
+	})
+}
+
+// serveBankVersion 题库历史版本类POST请求的公共处理流程
+func serveBankVersion(w http.ResponseWriter, r *http.Request, action string, apply func(e *ExamService, req BankVersionRequest) (BankVersionResponse, error)) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req BankVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response, err := apply(examService, req)
+	if err != nil {
+		response := BankVersionResponse{
+			Success: false,
+			Message: action + "失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response.Success = true
+	response.Message = action + "成功"
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
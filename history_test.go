@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBankHistoryDeltas(t *testing.T) {
+	useTempAppData(t)
+	e := &ExamService{}
+
+	items := make([]AnswerItem, 40)
+	for i := range items {
+		items[i] = AnswerItem{Type: QuestionTypeSingle, Question: fmt.Sprintf("第%d题", i+1), Options: []string{"对", "错"}, Answer: []string{"对"}}
+	}
+	if err := e.SetBankAnswers("历史", items); err != nil {
+		t.Fatal(err)
+	}
+
+	// 记录每个版本对应的题目，之后逐个还原比较
+	states := map[int][]AnswerItem{}
+	record := func() {
+		t.Helper()
+		versions, err := e.ListBankVersions("历史")
+		if err != nil {
+			t.Fatal(err)
+		}
+		answers, err := e.GetBankAnswers("历史")
+		if err != nil {
+			t.Fatal(err)
+		}
+		states[versions[len(versions)-1].Version] = answers
+	}
+	record()
+
+	for i := 0; i < 2*maxBankVersions; i++ {
+		answers, _ := e.GetBankAnswers("历史")
+		switch i % 4 {
+		case 0, 1:
+			item := answers[i%len(answers)]
+			item.Explanation = fmt.Sprintf("第%d次修改", i)
+			if _, err := e.UpdateQuestion("历史", item.ID, item); err != nil {
+				t.Fatal(err)
+			}
+		case 2:
+			if _, err := e.CreateQuestion("历史", AnswerItem{Question: fmt.Sprintf("新增%d", i), Options: []string{"对", "错"}, Answer: []string{"错"}}); err != nil {
+				t.Fatal(err)
+			}
+		case 3:
+			if err := e.DeleteQuestion("历史", answers[0].ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		record()
+	}
+	// 回滚会改变题目顺序
+	if err := e.RollbackBank("历史", 1+2*maxBankVersions-5); err != nil {
+		t.Fatal(err)
+	}
+	record()
+
+	versions, err := e.ListBankVersions("历史")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) < maxBankVersions || versions[0].Delta {
+		t.Fatalf("保留了 %d 个版本，最早的版本为差异: %v", len(versions), versions[0].Delta)
+	}
+	dir, _ := bankHistoryDir("历史")
+	snapshot, err := os.Stat(bankSnapshotPath(dir, versions[0].Version))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deltas := 0
+	for _, v := range versions {
+		got, err := loadBankVersion("历史", v.Version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, states[v.Version]) {
+			t.Errorf("版本 %d 还原的题目不一致", v.Version)
+		}
+		if !v.Delta {
+			continue
+		}
+		deltas++
+		info, err := os.Stat(bankSnapshotPath(dir, v.Version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > snapshot.Size()/3 {
+			t.Errorf("版本 %d 的差异有 %d 字节，完整快照 %d 字节", v.Version, info.Size(), snapshot.Size())
+		}
+	}
+	if deltas == 0 {
+		t.Error("没有保存差异版本")
+	}
+	if _, err := os.Stat(bankSnapshotPath(dir, 1)); !os.IsNotExist(err) {
+		t.Errorf("超出保留数量的版本文件应被删除: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, bankVersionsFileName)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ImportRowIssue 导入时被跳过的一行及原因
+type ImportRowIssue struct {
+	Line   int    `json:"line"`   // 文件中的行号（CSV为物理行号，Excel为工作表行号，JSON为第几道题，Moodle XML和GIFT为题目开始的行号，Word为题目开始的段落序号），从1开始
+	Reason string `json:"reason"` // 跳过原因
+}
+
+// ImportReport 逐行导入报告
+type ImportReport struct {
+	Strict   bool             `json:"strict"`   // 是否为严格模式：任意一行有问题即整体失败
+	Total    int              `json:"total"`    // 数据行数，不含标题行和空白行
+	Accepted int              `json:"accepted"` // 成功导入的题目数
+	Skipped  []ImportRowIssue `json:"skipped"`  // 被跳过的行
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	Items    []AnswerItem `json:"items"`
+	Encoding string       `json:"encoding,omitempty"` // 实际使用的文件编码，仅CSV导入
+	Report   ImportReport `json:"report"`
+
+	Separators DetectedSeparators `json:"separators"`     // 实际使用的分隔符
+	Bank       *BankFileHeader    `json:"bank,omitempty"` // 题库交换文件的文件头，仅JSON/JSONL导入
+}
+
+// Summary 导入结果的简要说明
+func (r ImportReport) Summary() string {
+	if len(r.Skipped) == 0 {
+		return fmt.Sprintf("成功导入 %d 道题", r.Accepted)
+	}
+	return fmt.Sprintf("成功导入 %d 道题，跳过 %d 行", r.Accepted, len(r.Skipped))
+}
+
+// importCollector 逐行收集导入结果和被跳过的行
+type importCollector struct {
+	result ImportResult
+}
+
+// newImportCollector 创建导入结果收集器
+func newImportCollector(strict bool) *importCollector {
+	return &importCollector{result: ImportResult{
+		Items:  []AnswerItem{},
+		Report: ImportReport{Strict: strict, Skipped: []ImportRowIssue{}},
+	}}
+}
+
+// skip 记录被跳过的一行
+func (c *importCollector) skip(line int, reason string) {
+	c.result.Report.Total++
+	c.result.Report.Skipped = append(c.result.Report.Skipped, ImportRowIssue{Line: line, Reason: reason})
+}
+
+// add 校验一道题，通过时加入结果，否则记录跳过原因
+func (c *importCollector) add(line int, item AnswerItem) {
+	if reason := validateAnswerItem(item); reason != "" {
+		c.skip(line, reason)
+		return
+	}
+	c.result.Report.Total++
+	c.result.Report.Accepted++
+	c.result.Items = append(c.result.Items, item)
+}
+
+// finish 返回导入结果。严格模式下只要有行被跳过就返回错误，且不返回任何题目，报告仍然完整
+func (c *importCollector) finish() (ImportResult, error) {
+	// 格式错误的行在读取时就已记录，按行号重新排列
+	sort.SliceStable(c.result.Report.Skipped, func(i, j int) bool {
+		return c.result.Report.Skipped[i].Line < c.result.Report.Skipped[j].Line
+	})
+
+	report := c.result.Report
+	if report.Strict && len(report.Skipped) > 0 {
+		first := report.Skipped[0]
+		c.result.Items = []AnswerItem{}
+		c.result.Report.Accepted = 0
+		return c.result, fmt.Errorf("严格模式下有 %d 行存在问题，第%d行: %s", len(report.Skipped), first.Line, first.Reason)
+	}
+	return c.result, nil
+}
+
+// validateAnswerItem 检查一道题能否导入，返回不能导入的原因，可以导入时返回空字符串
+func validateAnswerItem(item AnswerItem) string {
+	if strings.TrimSpace(item.Question) == "" {
+		return "缺少题目"
+	}
+
+	answers := []string{}
+	for _, ans := range item.Answer {
+		if ans = strings.TrimSpace(ans); ans != "" {
+			answers = append(answers, ans)
+		}
+	}
+	if len(answers) == 0 {
+		return "答案为空"
+	}
+
+	// 答案字母指向不存在的选项
+	if len(item.Warnings) > 0 {
+		return strings.Join(item.Warnings, "；")
+	}
+
+	// 有选项时，每个答案都必须是其中之一
+	options := map[string]bool{}
+	for _, option := range item.Options {
+		if option = strings.TrimSpace(option); option != "" {
+			options[option] = true
+		}
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	for _, ans := range answers {
+		if !options[ans] {
+			return "答案不在选项中: " + ans
+		}
+	}
+	return ""
+}
@@ -25,6 +25,21 @@ var assets embed.FS
 // logs any error that might occur.
 func main() {
 
+	// 加载上次保存的题库
+	if err := loadBanks(); err != nil {
+		log.Printf("加载题库失败: %v", err)
+	}
+
+	// 加载应用设置
+	if err := loadSettings(); err != nil {
+		log.Printf("加载设置失败: %v", err)
+	}
+
+	// 加载用户自定义的形近字表
+	if err := loadConfusions(); err != nil {
+		log.Printf("加载形近字表失败: %v", err)
+	}
+
 	// Create a new Wails application by providing the necessary options.
 	// Variables 'Name' and 'Description' are for application metadata.
 	// 'Assets' configures the asset server with the 'FS' variable pointing to the frontend files.
@@ -85,12 +100,73 @@ func startHTTPServer() {
 	// 注册CSV解析接口
 	mux.HandleFunc("/api/parse-csv", handleParseCSV)
 
+	// 注册Excel解析接口
+	mux.HandleFunc("/api/parse-excel", handleParseExcel)
+	mux.HandleFunc("/api/list-excel-sheets", handleListExcelSheets)
+
 	// 注册设置全局答案接口
 	mux.HandleFunc("/api/set-global-answers", handleSetGlobalAnswers)
 
 	// 注册获取全局答案接口
 	mux.HandleFunc("/api/get-global-answers", handleGetGlobalAnswers)
 
+	// 注册题库管理接口
+	mux.HandleFunc("/api/list-banks", handleListBanks)
+	mux.HandleFunc("/api/create-bank", handleCreateBank)
+	mux.HandleFunc("/api/rename-bank", handleRenameBank)
+	mux.HandleFunc("/api/delete-bank", handleDeleteBank)
+	mux.HandleFunc("/api/set-active-bank", handleSetActiveBank)
+
+	// 注册匹配参数接口
+	mux.HandleFunc("/api/get-matcher-config", handleGetMatcherConfig)
+	mux.HandleFunc("/api/set-matcher-config", handleSetMatcherConfig)
+
+	// 注册形近字表接口
+	mux.HandleFunc("/api/get-confusions", handleGetConfusions)
+	mux.HandleFunc("/api/import-confusions", handleImportConfusions)
+
+	// 注册题库文件导入导出接口
+	mux.HandleFunc("/api/export-bank", handleExportBank)
+	mux.HandleFunc("/api/import-bank", handleImportBank)
+
+	// 注册Moodle XML/GIFT解析接口
+	mux.HandleFunc("/api/parse-quiz", handleParseQuiz)
+
+	// 注册Word文档解析接口
+	mux.HandleFunc("/api/parse-docx", handleParseDocx)
+
+	// 注册文本题库解析接口
+	mux.HandleFunc("/api/parse-text", handleParseText)
+	mux.HandleFunc("/api/list-segment-presets", handleListSegmentPresets)
+
+	// 注册题库合并接口
+	mux.HandleFunc("/api/merge-bank", handleMergeBank)
+
+	// 注册单题增删改查接口
+	mux.HandleFunc("/api/get-question", handleGetQuestion)
+	mux.HandleFunc("/api/create-question", handleCreateQuestion)
+	mux.HandleFunc("/api/update-question", handleUpdateQuestion)
+	mux.HandleFunc("/api/delete-question", handleDeleteQuestion)
+	mux.HandleFunc("/api/patch-questions", handlePatchQuestions)
+
+	// 注册题库历史版本接口
+	mux.HandleFunc("/api/list-bank-versions", handleListBankVersions)
+	mux.HandleFunc("/api/diff-bank-versions", handleDiffBankVersions)
+	mux.HandleFunc("/api/rollback-bank", handleRollbackBank)
+
+	// 注册题目属性统计接口
+	mux.HandleFunc("/api/list-bank-facets", handleListBankFacets)
+
+	// 注册题型接口
+	mux.HandleFunc("/api/get-question-types", handleGetQuestionTypes)
+	mux.HandleFunc("/api/set-type-aliases", handleSetTypeAliases)
+
+	// 注册导入列映射接口
+	mux.HandleFunc("/api/get-column-mapping", handleGetColumnMapping)
+	mux.HandleFunc("/api/set-header-aliases", handleSetHeaderAliases)
+	mux.HandleFunc("/api/save-column-profile", handleSaveColumnProfile)
+	mux.HandleFunc("/api/delete-column-profile", handleDeleteColumnProfile)
+
 	// 注册OCR测试接口
 	mux.HandleFunc("/api/test-ocr", handleTestOCR)
 
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// MatcherConfig 匹配算法参数，可根据OCR识别质量调整
+type MatcherConfig struct {
+	OptionWeight float64 `json:"optionWeight"` // 选项匹配分数的权重
+
+	ContainmentMin float64 `json:"containmentMin"` // 查询文本被题目包含时的最低分
+	ContainmentMax float64 `json:"containmentMax"` // 包含匹配的最高分，题目被查询文本包含时直接取该值
+
+	EditWeight    float64 `json:"editWeight"`    // 综合评分中编辑距离相似度的权重
+	KeywordWeight float64 `json:"keywordWeight"` // 综合评分中关键词相似度的权重
+	CharWeight    float64 `json:"charWeight"`    // 综合评分中字符相似度的权重
+
+	EditSimilarityCutoff float64 `json:"editSimilarityCutoff"` // 没有共同关键词时编辑距离相似度的最低要求
+	EditFallbackWeight   float64 `json:"editFallbackWeight"`   // 没有共同关键词时编辑距离相似度的折算系数
+	MinSimilarity        float64 `json:"minSimilarity"`        // 综合评分低于该值视为不匹配
+
+	HighThreshold   float64 `json:"highThreshold"`   // 高准确率分段下限
+	MediumThreshold float64 `json:"mediumThreshold"` // 中准确率分段下限
+}
+
+// DefaultMatcherConfig 返回默认匹配参数
+func DefaultMatcherConfig() MatcherConfig {
+	return MatcherConfig{
+		OptionWeight:         0.8,
+		ContainmentMin:       0.9,
+		ContainmentMax:       0.95,
+		EditWeight:           0.2,
+		KeywordWeight:        0.5,
+		CharWeight:           0.3,
+		EditSimilarityCutoff: 0.3,
+		EditFallbackWeight:   0.6,
+		MinSimilarity:        0.1,
+		HighThreshold:        0.8,
+		MediumThreshold:      0.5,
+	}
+}
+
+// validate 校验参数取值范围
+func (c MatcherConfig) validate() error {
+	values := []struct {
+		name  string
+		value float64
+	}{
+		{"optionWeight", c.OptionWeight},
+		{"containmentMin", c.ContainmentMin},
+		{"containmentMax", c.ContainmentMax},
+		{"editWeight", c.EditWeight},
+		{"keywordWeight", c.KeywordWeight},
+		{"charWeight", c.CharWeight},
+		{"editSimilarityCutoff", c.EditSimilarityCutoff},
+		{"editFallbackWeight", c.EditFallbackWeight},
+		{"minSimilarity", c.MinSimilarity},
+		{"highThreshold", c.HighThreshold},
+		{"mediumThreshold", c.MediumThreshold},
+	}
+	for _, v := range values {
+		if v.value < 0 || v.value > 1 {
+			return fmt.Errorf("%s 必须在0到1之间，当前为%v", v.name, v.value)
+		}
+	}
+
+	if c.ContainmentMin > c.ContainmentMax {
+		return fmt.Errorf("containmentMin 不能大于 containmentMax")
+	}
+	if c.MediumThreshold > c.HighThreshold {
+		return fmt.Errorf("mediumThreshold 不能大于 highThreshold")
+	}
+	if c.EditWeight+c.KeywordWeight+c.CharWeight == 0 {
+		return fmt.Errorf("editWeight、keywordWeight、charWeight 不能同时为0")
+	}
+	return nil
+}
+
+// currentMatcherConfig 返回当前生效的匹配参数
+func currentMatcherConfig() MatcherConfig {
+	return currentSettings().Matcher
+}
+
+// GetMatcherConfig 获取匹配参数
+func (e *ExamService) GetMatcherConfig() MatcherConfig {
+	return currentMatcherConfig()
+}
+
+// SetMatcherConfig 设置匹配参数并保存
+func (e *ExamService) SetMatcherConfig(config MatcherConfig) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+	return updateSettings(func(s *AppSettings) error {
+		s.Matcher = config
+		return nil
+	})
+}
+
+// ResetMatcherConfig 恢复默认匹配参数
+func (e *ExamService) ResetMatcherConfig() (MatcherConfig, error) {
+	config := DefaultMatcherConfig()
+	if err := e.SetMatcherConfig(config); err != nil {
+		return MatcherConfig{}, err
+	}
+	return config, nil
+}
+
+// MatcherConfigRequest HTTP设置匹配参数请求结构
+type MatcherConfigRequest struct {
+	Config MatcherConfig `json:"config"`
+	Reset  bool          `json:"reset,omitempty"` // 为true时忽略config，恢复默认参数
+}
+
+// MatcherConfigResponse HTTP匹配参数响应结构
+type MatcherConfigResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+	Config  MatcherConfig `json:"config"`
+}
+
+// handleGetMatcherConfig 处理HTTP获取匹配参数请求
+func handleGetMatcherConfig(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response := MatcherConfigResponse{
+		Success: true,
+		Config:  examService.GetMatcherConfig(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleSetMatcherConfig 处理HTTP设置匹配参数请求
+func handleSetMatcherConfig(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	// 以当前参数为基础解析请求体，未提供的字段保持不变
+	req := MatcherConfigRequest{Config: examService.GetMatcherConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	if req.Reset {
+		_, err = examService.ResetMatcherConfig()
+	} else {
+		err = examService.SetMatcherConfig(req.Config)
+	}
+	if err != nil {
+		response := MatcherConfigResponse{
+			Success: false,
+			Message: "设置匹配参数失败: " + err.Error(),
+			Config:  examService.GetMatcherConfig(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := MatcherConfigResponse{
+		Success: true,
+		Message: "匹配参数已保存",
+		Config:  examService.GetMatcherConfig(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// 合并时同一道题答案不同的处理方式
+const (
+	MergeKeepExisting = "keep-existing" // 保留已有题目，丢弃新题目
+	MergeKeepNew      = "keep-new"      // 用新题目替换已有题目
+	MergeKeepBoth     = "keep-both"     // 两道题都保留
+)
+
+// defaultNearDuplicateThreshold 近似重复的默认相似度下限
+const defaultNearDuplicateThreshold = 0.9
+
+// nearDuplicateMinTermShare 近似重复的候选题目至少包含新题目中这一比例的词项，
+// 不满足的不计算相似度。相似度0.9以上的两道题只有个别字不同，共有的词项远多于一半
+const nearDuplicateMinTermShare = 0.5
+
+// nearDuplicateMinLengthRatio 近似重复的两道题，题目长度之比的下限。
+// 包含匹配的得分很高，短题目被长题目包含时不应视为同一道题
+const nearDuplicateMinLengthRatio = 0.8
+
+// MergeRequest 合并题库请求结构，新题目来自Answers或SourceBank
+type MergeRequest struct {
+	Bank       string       `json:"bank,omitempty"`       // 目标题库，为空时合并到当前激活的题库
+	Answers    []AnswerItem `json:"answers,omitempty"`    // 要合并的新题目
+	SourceBank string       `json:"sourceBank,omitempty"` // 要合并的题库，Answers为空时使用
+
+	Threshold   float64        `json:"threshold,omitempty"`   // 近似重复的相似度下限（0-1），为0时使用默认值0.9
+	Resolution  string         `json:"resolution,omitempty"`  // 冲突的默认处理方式，为空时保留已有题目
+	Resolutions map[int]string `json:"resolutions,omitempty"` // 按新题目下标逐个指定冲突的处理方式
+	DryRun      bool           `json:"dryRun,omitempty"`      // 只分析不保存，用于先查看冲突再决定处理方式
+}
+
+// MergeMatch 新题目与已有题目的对应关系
+type MergeMatch struct {
+	Incoming   int     `json:"incoming"`   // 新题目下标
+	Existing   int     `json:"existing"`   // 对应题目在合并后题库中的下标
+	Exact      bool    `json:"exact"`      // 标准化后完全相同，否则为近似重复
+	Similarity float64 `json:"similarity"` // 题目相似度
+}
+
+// MergeConflict 同一道题答案不同的冲突
+type MergeConflict struct {
+	MergeMatch
+	ExistingItem AnswerItem `json:"existingItem"`
+	NewItem      AnswerItem `json:"newItem"`
+	Resolution   string     `json:"resolution"` // 实际采用的处理方式
+}
+
+// MergeResult 合并结果
+type MergeResult struct {
+	Bank       string          `json:"bank"`
+	DryRun     bool            `json:"dryRun"`
+	Added      int             `json:"added"`      // 新增的题目数，含冲突中保留两者的
+	Replaced   int             `json:"replaced"`   // 冲突中用新题目替换的数量
+	Duplicates []MergeMatch    `json:"duplicates"` // 与已有题目重复而跳过的新题目
+	Conflicts  []MergeConflict `json:"conflicts"`  // 答案不同的冲突
+	Count      int             `json:"count"`      // 合并后的题目总数
+}
+
+// mergeFingerprint 用于比较的标准化题目内容
+type mergeFingerprint struct {
+	question string // 标准化后的题目
+	options  string // 去掉标号并排序后的选项
+	answers  string // 去掉标号并排序后的答案
+	length   int    // 题目字符数
+}
+
+// key 题目和选项组成的键，相同时视为同一道题
+func (fp mergeFingerprint) key() string {
+	return fp.question + "\x01" + fp.options
+}
+
+// fingerprint 生成题目的标准化内容，选项和答案去掉字母标号并排序，顺序不同视为相同
+func (e *ExamService) fingerprint(item AnswerItem) mergeFingerprint {
+	normalizeAll := func(values []string) string {
+		normalized := []string{}
+		for _, value := range values {
+			_, text := optionLabel(value)
+			if text = strings.ToLower(e.normalizeText(text)); text != "" {
+				normalized = append(normalized, text)
+			}
+		}
+		sort.Strings(normalized)
+		return strings.Join(normalized, "\x00")
+	}
+	question := strings.ToLower(e.normalizeText(item.Question))
+	return mergeFingerprint{
+		question: question,
+		options:  normalizeAll(item.Options),
+		answers:  normalizeAll(item.Answer),
+		length:   utf8.RuneCountInString(question),
+	}
+}
+
+// similarity 计算两道题的相似度，选项不同的不是同一道题。
+// 搜索的评分算法面向短查询，整题比较时个别字不同也会拉低关键词得分，
+// 因此同时按字符计算编辑距离相似度，取两者中较高的
+func (e *ExamService) similarity(a, b mergeFingerprint, cfg MatcherConfig) float64 {
+	if a.length == 0 || b.length == 0 {
+		return 0
+	}
+	if float64(min(a.length, b.length))/float64(max(a.length, b.length)) < nearDuplicateMinLengthRatio {
+		return 0
+	}
+	score := e.textSimilarity(a.question, b.question, cfg)
+	if a.options != b.options {
+		if optionScore := e.textSimilarity(a.options, b.options, cfg); optionScore < score {
+			score = optionScore
+		}
+	}
+	return score
+}
+
+// textSimilarity 取评分算法得分与按字符计算的编辑距离相似度中较高的
+func (e *ExamService) textSimilarity(a, b string, cfg MatcherConfig) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	score, _ := e.calculateOverlapScore(a, b, cfg)
+	runesA, runesB := []rune(a), []rune(b)
+	edit := 1 - e.calculateEditDistance(runesA, runesB)/float64(max(len(runesA), len(runesB)))
+	if edit > score {
+		score = edit
+	}
+	return score
+}
+
+// MergeBank 将新题目合并到题库：完全相同和近似重复且答案相同的题目跳过，
+// 同一道题答案不同时按指定方式处理。DryRun为true时只返回分析结果
+func (e *ExamService) MergeBank(req MergeRequest) (MergeResult, error) {
+	threshold := req.Threshold
+	if threshold <= 0 {
+		threshold = defaultNearDuplicateThreshold
+	}
+	if threshold > 1 {
+		return MergeResult{}, fmt.Errorf("相似度下限必须在0到1之间: %v", threshold)
+	}
+	defaultResolution := req.Resolution
+	if defaultResolution == "" {
+		defaultResolution = MergeKeepExisting
+	}
+	for _, resolution := range append([]string{defaultResolution}, mapValues(req.Resolutions)...) {
+		switch resolution {
+		case MergeKeepExisting, MergeKeepNew, MergeKeepBoth:
+		default:
+			return MergeResult{}, fmt.Errorf("不支持的冲突处理方式: %s，可选: %s、%s、%s", resolution, MergeKeepExisting, MergeKeepNew, MergeKeepBoth)
+		}
+	}
+
+	incoming := req.Answers
+	if len(incoming) == 0 && req.SourceBank != "" {
+		source, err := findBank(req.SourceBank)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		incoming = source.Answers
+	}
+
+	// 在快照上计算合并结果，保存时确认题库没有被修改
+	banks := currentBanks()
+	name := req.Bank
+	if name == "" {
+		name = banks.active
+	}
+	if name == "" {
+		name = defaultBankName
+	}
+	target := banks.find(name)
+	if req.SourceBank == name && len(req.Answers) == 0 {
+		return MergeResult{}, fmt.Errorf("不能将题库合并到自身: %s", name)
+	}
+
+	existing := []AnswerItem{}
+	var index *searchIndex
+	if target != nil {
+		existing, index = target.Answers, target.index
+	}
+
+	result := MergeResult{Bank: name, DryRun: req.DryRun, Duplicates: []MergeMatch{}, Conflicts: []MergeConflict{}}
+	merged := append([]AnswerItem{}, existing...)
+	cfg := currentMatcherConfig()
+
+	// 按标准化内容查找完全相同的题目，包括本次新增的题目。近似重复只与合并前的题目比较
+	fingerprints := make([]mergeFingerprint, 0, len(existing)+len(incoming))
+	exact := map[string]int{}
+	remember := func(i int, fp mergeFingerprint) {
+		if _, ok := exact[fp.key()]; !ok {
+			exact[fp.key()] = i
+		}
+	}
+	for i, item := range existing {
+		fp := e.fingerprint(item)
+		fingerprints = append(fingerprints, fp)
+		remember(i, fp)
+	}
+
+	for i, item := range incoming {
+		fp := e.fingerprint(item)
+		match, found := MergeMatch{Incoming: i, Existing: -1}, false
+		if j, ok := exact[fp.key()]; ok {
+			match.Existing, match.Exact, match.Similarity, found = j, true, 1, true
+		} else if index != nil {
+			// 用已有题目的索引筛选共有词项足够多的候选，再逐一计算相似度
+			if candidates, ok := index.candidatesSharing(e.indexTerms(item.Question), nearDuplicateMinTermShare); ok {
+				for _, j := range candidates {
+					if score := e.similarity(fp, fingerprints[j], cfg); score >= threshold && score > match.Similarity {
+						match.Existing, match.Similarity, found = j, score, true
+					}
+				}
+			}
+		}
+
+		if !found {
+			merged = append(merged, item)
+			fingerprints = append(fingerprints, fp)
+			remember(len(merged)-1, fp)
+			result.Added++
+			continue
+		}
+		if fp.answers == fingerprints[match.Existing].answers {
+			result.Duplicates = append(result.Duplicates, match)
+			continue
+		}
+
+		resolution, ok := req.Resolutions[i]
+		if !ok {
+			resolution = defaultResolution
+		}
+		conflict := MergeConflict{MergeMatch: match, ExistingItem: merged[match.Existing], NewItem: item, Resolution: resolution}
+		result.Conflicts = append(result.Conflicts, conflict)
+
+		switch resolution {
+		case MergeKeepNew:
+			// 替换后沿用原题目的ID
+			item.ID = merged[match.Existing].ID
+			merged[match.Existing] = item
+			fingerprints[match.Existing] = fp
+			result.Replaced++
+		case MergeKeepBoth:
+			merged = append(merged, item)
+			fingerprints = append(fingerprints, fp)
+			result.Added++
+		}
+	}
+	result.Count = len(merged)
+
+	if req.DryRun || (result.Added == 0 && result.Replaced == 0) {
+		return result, nil
+	}
+	return result, storeBankAnswers(name, merged, func(current *QuestionBank) error {
+		if current != target {
+			return fmt.Errorf("题库在合并过程中被修改，请重新合并: %s", name)
+		}
+		return nil
+	}, bankChange{action: BankActionMerge, summary: result.Summary()})
+}
+
+// mapValues 返回map中的全部值
+func mapValues(m map[int]string) []string {
+	values := make([]string, 0, len(m))
+	for _, value := range m {
+		values = append(values, value)
+	}
+	return values
+}
+
+// MergeBankResponse HTTP合并题库响应结构
+type MergeBankResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Result  *MergeResult `json:"result,omitempty"`
+}
+
+// Summary 合并结果的简要说明
+func (r MergeResult) Summary() string {
+	action := "合并完成"
+	if r.DryRun {
+		action = "合并预览"
+	}
+	return fmt.Sprintf("%s：新增 %d 道题，替换 %d 道题，跳过重复 %d 道题，冲突 %d 处，合并后共 %d 道题",
+		action, r.Added, r.Replaced, len(r.Duplicates), len(r.Conflicts), r.Count)
+}
+
+// handleMergeBank 处理HTTP合并题库请求
+func handleMergeBank(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	result, err := examService.MergeBank(req)
+	if err != nil {
+		response := MergeBankResponse{
+			Success: false,
+			Message: "合并题库失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := MergeBankResponse{
+		Success: true,
+		Message: result.Summary(),
+		Result:  &result,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// randomQuestions 生成由随机汉字组成的题目，每道题40个字，四个选项。
+// 只从300个汉字中选取，使题目之间像真实题库一样有大量共同的二元组
+func randomQuestions(n int, seed int64) []AnswerItem {
+	r := rand.New(rand.NewSource(seed))
+	text := func(length int) string {
+		var b strings.Builder
+		for i := 0; i < length; i++ {
+			b.WriteRune(rune(0x4E00 + r.Intn(300)))
+		}
+		return b.String()
+	}
+	items := make([]AnswerItem, 0, n)
+	for i := 0; i < n; i++ {
+		options := []string{"A. " + text(4), "B. " + text(4), "C. " + text(4), "D. " + text(4)}
+		items = append(items, AnswerItem{Question: text(40), Options: options, Answer: []string{options[0]}})
+	}
+	return items
+}
+
+func TestMergeBankNearDuplicate(t *testing.T) {
+	useTempAppData(t)
+	e := &ExamService{}
+	existing := randomQuestions(2000, 1)
+	if err := e.SetBankAnswers("合并", existing); err != nil {
+		t.Fatal(err)
+	}
+
+	// 改动一个字的题目仍是近似重复，答案不同时记为冲突
+	edited := existing[1234]
+	question := []rune(edited.Question)
+	question[20] = '改'
+	edited.Question = string(question)
+	edited.Answer = []string{edited.Options[1]}
+	incoming := append([]AnswerItem{edited}, randomQuestions(1, 2)...)
+	result, err := e.MergeBank(MergeRequest{Bank: "合并", Answers: incoming, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0].Existing != 1234 || result.Conflicts[0].Exact {
+		t.Fatalf("冲突 = %+v", result.Conflicts)
+	}
+	if result.Added != 1 {
+		t.Errorf("新增 %d 道题，期望 1 道", result.Added)
+	}
+}
+
+// BenchmarkMergeBank 5000道题的题库试合并500道新题目
+func BenchmarkMergeBank(b *testing.B) {
+	useTempAppData(b)
+	e := &ExamService{}
+	if err := e.SetBankAnswers("合并", randomQuestions(5000, 1)); err != nil {
+		b.Fatal(err)
+	}
+	req := MergeRequest{Bank: "合并", Answers: randomQuestions(500, 2), DryRun: true}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := e.MergeBank(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tagSeparatorPattern 标签单元格中标签之间的分隔符
+var tagSeparatorPattern = regexp.MustCompile(`[,，、;；|\r\n]+`)
+
+// exportTagSeparator 导出时标签之间的分隔符
+const exportTagSeparator = ","
+
+// splitTags 拆分标签单元格，去掉空白和重复的标签，没有标签时返回nil
+func splitTags(cell string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range tagSeparatorPattern.Split(cell, -1) {
+		if tag = strings.TrimSpace(tag); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// setSource 为没有记录来源的题目填入导入的文件名
+func (r *ImportResult) setSource(filePath string) {
+	if filePath == "" {
+		return
+	}
+	name := filepath.Base(filePath)
+	for i := range r.Items {
+		if r.Items[i].Source == "" {
+			r.Items[i].Source = name
+		}
+	}
+}
+
+// scoped 判断是否按题目属性限定了搜索范围
+func (f SearchFilters) scoped() bool {
+	return len(f.Chapters) > 0 || len(f.Categories) > 0 || len(f.Tags) > 0 || len(f.Types) > 0
+}
+
+// matches 判断题目是否在限定的范围内，比较时忽略首尾空白和大小写。
+// 题型按标准题型比较，f.Types需要事先转换为标准题型
+func (f SearchFilters) matches(item AnswerItem, types typeResolver) bool {
+	if !matchesAny([]string{item.Chapter}, f.Chapters) || !matchesAny([]string{item.Category}, f.Categories) {
+		return false
+	}
+	return matchesAny(item.Tags, f.Tags) && matchesAny([]string{types.resolve(item)}, f.Types)
+}
+
+// matchesAny 判断values中是否有任意一个在wanted中，wanted为空时总是满足
+func matchesAny(values []string, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		for _, w := range wanted {
+			if value != "" && strings.EqualFold(value, strings.TrimSpace(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scope 返回限定了搜索范围的搜索目标，仍使用题库的索引筛选候选题目
+func (t searchTarget) scope(f SearchFilters) searchTarget {
+	types := newTypeResolver()
+	f.Types = types.labels(f.Types)
+	t.allowed = make([]bool, len(t.answers))
+	for i, item := range t.answers {
+		t.allowed[i] = f.matches(item, types)
+	}
+	return t
+}
+
+// FacetCount 题目属性的一个取值及题目数量
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// BankFacets 题库中题目属性的全部取值，用于选择搜索范围
+type BankFacets struct {
+	Bank       string       `json:"bank"`
+	Chapters   []FacetCount `json:"chapters"`
+	Categories []FacetCount `json:"categories"`
+	Tags       []FacetCount `json:"tags"`
+	Types      []FacetCount `json:"types"`
+}
+
+// ListBankFacets 统计题库中的章节、分类、标签和标准题型，题目多的取值在前，name为空时使用当前激活的题库
+func (e *ExamService) ListBankFacets(name string) (BankFacets, error) {
+	bank, err := findBank(name)
+	if err != nil {
+		return BankFacets{}, err
+	}
+
+	facets := BankFacets{Bank: bank.Name}
+	resolver := newTypeResolver()
+	chapters, categories, tags, types := facetCounter{}, facetCounter{}, facetCounter{}, facetCounter{}
+	for _, item := range bank.Answers {
+		chapters.add(item.Chapter)
+		categories.add(item.Category)
+		types.add(resolver.resolve(item))
+		for _, tag := range item.Tags {
+			tags.add(tag)
+		}
+	}
+	facets.Chapters = chapters.list()
+	facets.Categories = categories.list()
+	facets.Tags = tags.list()
+	facets.Types = types.list()
+	return facets, nil
+}
+
+// facetCounter 按首次出现的顺序统计取值
+type facetCounter struct {
+	counts []FacetCount
+	index  map[string]int
+}
+
+// add 记录一个取值，空值不统计
+func (c *facetCounter) add(value string) {
+	if value = strings.TrimSpace(value); value == "" {
+		return
+	}
+	if c.index == nil {
+		c.index = map[string]int{}
+	}
+	if i, ok := c.index[value]; ok {
+		c.counts[i].Count++
+		return
+	}
+	c.index[value] = len(c.counts)
+	c.counts = append(c.counts, FacetCount{Value: value, Count: 1})
+}
+
+// list 返回统计结果，题目数量多的在前，数量相同时保持首次出现的顺序
+func (c *facetCounter) list() []FacetCount {
+	counts := append([]FacetCount{}, c.counts...)
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+	return counts
+}
+
+// BankFacetsResponse HTTP题目属性统计响应结构
+type BankFacetsResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message,omitempty"`
+	Facets  *BankFacets `json:"facets,omitempty"`
+}
+
+// handleListBankFacets 处理HTTP题目属性统计请求，参数为bank
+func handleListBankFacets(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	facets, err := examService.ListBankFacets(r.URL.Query().Get("bank"))
+	if err != nil {
+		response := BankFacetsResponse{
+			Success: false,
+			Message: "获取题目属性失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := BankFacetsResponse{
+		Success: true,
+		Facets:  &facets,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// moodleText Moodle XML中带格式的文本节点，如<questiontext format="html"><text>...</text></questiontext>
+type moodleText struct {
+	Format string `xml:"format,attr"`
+	Text   string `xml:"text"`
+}
+
+// plain 返回纯文本，HTML格式的内容去掉标签
+func (t moodleText) plain() string {
+	switch t.Format {
+	case "", "html", "moodle_auto_format":
+		return htmlToText(t.Text)
+	default:
+		return strings.TrimSpace(t.Text)
+	}
+}
+
+// moodleAnswer Moodle XML中的一个答案，fraction为该答案的得分百分比
+type moodleAnswer struct {
+	Fraction string `xml:"fraction,attr"`
+	moodleText
+}
+
+// moodleQuestion Moodle XML中的一道题，type="category"的节点只用于指定之后题目的分类
+type moodleQuestion struct {
+	Type            string         `xml:"type,attr"`
+	Name            moodleText     `xml:"name"`
+	QuestionText    moodleText     `xml:"questiontext"`
+	GeneralFeedback moodleText     `xml:"generalfeedback"`
+	Category        moodleText     `xml:"category"`
+	Single          string         `xml:"single"`
+	Answers         []moodleAnswer `xml:"answer"`
+	Tags            []moodleText   `xml:"tags>tag"`
+}
+
+// parseMoodleXML 逐题解析Moodle XML，报告中的行号为<question>标签所在的行
+func parseMoodleXML(content string, strict bool) (ImportResult, error) {
+	collector := newImportCollector(strict)
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	// 内容已按文件编码解码为UTF-8，忽略XML声明中的encoding
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	category := ""
+	found := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return collector.result, fmt.Errorf("解析Moodle XML失败: %v", err)
+		}
+		line, _ := decoder.InputPos()
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "question" {
+			continue
+		}
+		found = true
+
+		var question moodleQuestion
+		if err := decoder.DecodeElement(&question, &start); err != nil {
+			return collector.result, fmt.Errorf("解析第%d行的题目失败: %v", line, err)
+		}
+		if question.Type == "category" {
+			category = moodleCategoryName(question.Category.Text)
+			continue
+		}
+
+		item, reason := question.answerItem()
+		if reason != "" {
+			collector.skip(line, reason)
+			continue
+		}
+		item.Category = category
+		collector.add(line, item)
+	}
+
+	if !found {
+		return collector.result, fmt.Errorf("不是Moodle XML文件: 没有找到question节点")
+	}
+	return collector.finish()
+}
+
+// moodleCategoryName 取分类路径的最后一级作为分类名称，如"$course$/top/第一章"为"第一章"
+func moodleCategoryName(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		path = path[i+1:]
+	}
+	if path == "$course$" || path == "$system$" || path == "top" {
+		return ""
+	}
+	return path
+}
+
+// answerItem 将Moodle题目转换为答案项，题型不支持时返回原因
+func (q moodleQuestion) answerItem() (AnswerItem, string) {
+	item := AnswerItem{
+		Question:    q.QuestionText.plain(),
+		Options:     []string{},
+		Answer:      []string{},
+		Explanation: q.GeneralFeedback.plain(),
+	}
+	if item.Question == "" {
+		item.Question = q.Name.plain()
+	}
+	for _, tag := range q.Tags {
+		item.Tags = append(item.Tags, splitTags(tag.plain())...)
+	}
+
+	switch q.Type {
+	case "multichoice":
+		// single缺省为true；单选题只有满分答案是正确答案，多选题每个得分为正的答案都是正确答案
+		single := !strings.EqualFold(strings.TrimSpace(q.Single), "false") && strings.TrimSpace(q.Single) != "0"
+		item.Type = QuestionTypeSingle
+		if !single {
+			item.Type = QuestionTypeMultiple
+		}
+		// 选项是完整文本，答案字母按选项顺序确定，不再从答案文本解析
+		for i, answer := range q.Answers {
+			text := answer.plain()
+			item.Options = append(item.Options, text)
+			fraction := moodleFraction(answer.Fraction)
+			if (single && fraction >= 100) || (!single && fraction > 0) {
+				item.Answer = append(item.Answer, text)
+				if key := optionKey(i); key != "" {
+					item.AnswerKeys = append(item.AnswerKeys, key)
+				}
+			}
+		}
+	case "truefalse":
+		item.Type = QuestionTypeJudge
+		item.Options = []string{judgeTrue, judgeFalse}
+		for _, answer := range q.Answers {
+			if moodleFraction(answer.Fraction) < 100 {
+				continue
+			}
+			if strings.EqualFold(answer.plain(), "true") {
+				item.Answer = append(item.Answer, judgeTrue)
+				item.AnswerKeys = append(item.AnswerKeys, optionKey(0))
+			} else {
+				item.Answer = append(item.Answer, judgeFalse)
+				item.AnswerKeys = append(item.AnswerKeys, optionKey(1))
+			}
+		}
+	case "shortanswer":
+		// 所有满分答案都是可接受的答案
+		item.Type = QuestionTypeBlank
+		for _, answer := range q.Answers {
+			if moodleFraction(answer.Fraction) >= 100 {
+				item.Answer = append(item.Answer, answer.plain())
+			}
+		}
+	default:
+		return item, "不支持的题型: " + q.Type
+	}
+	return item, ""
+}
+
+// moodleFraction 解析答案得分百分比，无法解析时视为0分
+func moodleFraction(s string) float64 {
+	fraction, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0
+	}
+	return fraction
+}
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// QuestionPatch 批量修改中的一项，只修改不为nil的字段，Delete为true时删除该题
+type QuestionPatch struct {
+	ID     string `json:"id"`
+	Delete bool   `json:"delete,omitempty"`
+
+	Type        *string   `json:"type,omitempty"`
+	Question    *string   `json:"question,omitempty"`
+	Options     *[]string `json:"options,omitempty"`
+	Answer      *[]string `json:"answer,omitempty"`
+	Explanation *string   `json:"explanation,omitempty"`
+	Category    *string   `json:"category,omitempty"`
+	Difficulty  *string   `json:"difficulty,omitempty"`
+	Chapter     *string   `json:"chapter,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+	Source      *string   `json:"source,omitempty"`
+}
+
+// apply 将修改应用到题目上
+func (p QuestionPatch) apply(item AnswerItem) AnswerItem {
+	if p.Type != nil {
+		item.Type = *p.Type
+	}
+	if p.Question != nil {
+		item.Question = *p.Question
+	}
+	if p.Options != nil {
+		item.Options = *p.Options
+	}
+	if p.Answer != nil {
+		item.Answer = *p.Answer
+	}
+	if p.Explanation != nil {
+		item.Explanation = *p.Explanation
+	}
+	if p.Category != nil {
+		item.Category = *p.Category
+	}
+	if p.Difficulty != nil {
+		item.Difficulty = *p.Difficulty
+	}
+	if p.Chapter != nil {
+		item.Chapter = *p.Chapter
+	}
+	if p.Tags != nil {
+		item.Tags = *p.Tags
+	}
+	if p.Source != nil {
+		item.Source = *p.Source
+	}
+	return item
+}
+
+// indexOf 返回指定ID的题目下标，不存在时返回-1
+func (b *QuestionBank) indexOf(id string) int {
+	for i, item := range b.Answers {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// prepareQuestion 整理并校验要保存的题目。答案是完整的选项文本，按修改后的选项文本
+// 重新计算答案字母，不把答案当作选项字母解析，调整选项顺序不会改变答案
+func prepareQuestion(item AnswerItem) (AnswerItem, error) {
+	item.Question = strings.TrimSpace(item.Question)
+	item.AnswerKeys = nil
+	item.Warnings = nil
+	if item.Options == nil {
+		item.Options = []string{}
+	}
+	if item.Answer == nil {
+		item.Answer = []string{}
+	}
+	item = resolveAnswerText(item)
+	if reason := validateAnswerItem(item); reason != "" {
+		return item, fmt.Errorf("题目无法保存: %s", reason)
+	}
+	return item, nil
+}
+
+// GetQuestion 按ID获取题目，bankName为空时在当前激活的题库中查找
+func (e *ExamService) GetQuestion(bankName string, id string) (AnswerItem, error) {
+	bank, err := findBank(bankName)
+	if err != nil {
+		return AnswerItem{}, err
+	}
+	i := bank.indexOf(id)
+	if i < 0 {
+		return AnswerItem{}, fmt.Errorf("题目不存在: %s", id)
+	}
+	return bank.Answers[i], nil
+}
+
+// CreateQuestion 在题库末尾新增一道题，返回分配了ID的题目。
+// item.ID不为空时使用指定的ID，与已有题目重复时返回错误
+func (e *ExamService) CreateQuestion(bankName string, item AnswerItem) (AnswerItem, error) {
+	item, err := prepareQuestion(item)
+	if err != nil {
+		return item, err
+	}
+
+	change := bankChange{action: BankActionEdit, summary: "新增题目: " + briefQuestion(item.Question)}
+	err = updateBank(bankName, &change, func(bank *QuestionBank) (*QuestionBank, error) {
+		if item.ID != "" && bank.indexOf(item.ID) >= 0 {
+			return nil, fmt.Errorf("题目ID已存在: %s", item.ID)
+		}
+		for item.ID == "" || bank.indexOf(item.ID) >= 0 {
+			item.ID = newQuestionID()
+		}
+
+		answers := append(bank.Answers[:len(bank.Answers):len(bank.Answers)], item)
+		updated := *bank
+		updated.Answers = answers
+		updated.index = bank.index.update(bank.Answers, answers, []int{len(answers) - 1})
+		return &updated, nil
+	})
+	return item, err
+}
+
+// UpdateQuestion 替换指定ID的题目内容，ID保持不变
+func (e *ExamService) UpdateQuestion(bankName string, id string, item AnswerItem) (AnswerItem, error) {
+	item.ID = id
+	item, err := prepareQuestion(item)
+	if err != nil {
+		return item, err
+	}
+
+	change := bankChange{action: BankActionEdit, summary: "修改题目: " + briefQuestion(item.Question)}
+	err = updateBank(bankName, &change, func(bank *QuestionBank) (*QuestionBank, error) {
+		i := bank.indexOf(id)
+		if i < 0 {
+			return nil, fmt.Errorf("题目不存在: %s", id)
+		}
+
+		answers := append([]AnswerItem{}, bank.Answers...)
+		answers[i] = item
+		updated := *bank
+		updated.Answers = answers
+		updated.index = bank.index.update(bank.Answers, answers, []int{i})
+		return &updated, nil
+	})
+	return item, err
+}
+
+// DeleteQuestion 删除指定ID的题目
+func (e *ExamService) DeleteQuestion(bankName string, id string) error {
+	change := bankChange{action: BankActionDelete}
+	return updateBank(bankName, &change, func(bank *QuestionBank) (*QuestionBank, error) {
+		i := bank.indexOf(id)
+		if i < 0 {
+			return nil, fmt.Errorf("题目不存在: %s", id)
+		}
+		change.summary = "删除题目: " + briefQuestion(bank.Answers[i].Question)
+
+		answers := append(append([]AnswerItem{}, bank.Answers[:i]...), bank.Answers[i+1:]...)
+		updated := *bank
+		updated.Answers = answers
+		updated.index = bank.index.without(map[int]bool{i: true})
+		return &updated, nil
+	})
+}
+
+// PatchQuestions 批量修改或删除题目，任意一项失败时不做任何修改。
+// 返回修改后的题目（不含删除的），顺序与patches相同
+func (e *ExamService) PatchQuestions(bankName string, patches []QuestionPatch) ([]AnswerItem, error) {
+	if len(patches) == 0 {
+		return []AnswerItem{}, nil
+	}
+
+	deleted := 0
+	for _, patch := range patches {
+		if patch.Delete {
+			deleted++
+		}
+	}
+	change := bankChange{action: BankActionEdit, summary: fmt.Sprintf("批量修改 %d 道题", len(patches)-deleted)}
+	if deleted > 0 {
+		change = bankChange{action: BankActionDelete, summary: fmt.Sprintf("批量修改 %d 道题，删除 %d 道题", len(patches)-deleted, deleted)}
+	}
+
+	patched := []AnswerItem{}
+	err := updateBank(bankName, &change, func(bank *QuestionBank) (*QuestionBank, error) {
+		positions := make(map[string]int, len(bank.Answers))
+		for i, item := range bank.Answers {
+			positions[item.ID] = i
+		}
+
+		answers := append([]AnswerItem{}, bank.Answers...)
+		changed := []int{}
+		isChanged := map[int]bool{}
+		removed := map[int]bool{}
+		order := []int{}
+		for n, patch := range patches {
+			i, ok := positions[patch.ID]
+			if patch.ID == "" || !ok {
+				return nil, fmt.Errorf("第%d项: 题目不存在: %s", n+1, patch.ID)
+			}
+			if removed[i] {
+				return nil, fmt.Errorf("第%d项: 题目已被删除: %s", n+1, patch.ID)
+			}
+			if patch.Delete {
+				removed[i] = true
+				continue
+			}
+
+			item, err := prepareQuestion(patch.apply(answers[i]))
+			if err != nil {
+				return nil, fmt.Errorf("第%d项: %v", n+1, err)
+			}
+			answers[i] = item
+			if !isChanged[i] {
+				isChanged[i] = true
+				changed = append(changed, i)
+			}
+			order = append(order, i)
+		}
+
+		// 先按原下标更新索引，再删除题目
+		index := bank.index
+		if len(changed) > 0 {
+			index = index.update(bank.Answers, answers, changed)
+		}
+		for _, i := range order {
+			if !removed[i] {
+				patched = append(patched, answers[i])
+			}
+		}
+		if len(removed) > 0 {
+			kept := make([]AnswerItem, 0, len(answers)-len(removed))
+			for i, item := range answers {
+				if !removed[i] {
+					kept = append(kept, item)
+				}
+			}
+			answers = kept
+			index = index.without(removed)
+		}
+
+		updated := *bank
+		updated.Answers = answers
+		updated.index = index
+		return &updated, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// QuestionRequest HTTP单题操作请求结构
+type QuestionRequest struct {
+	Bank    string          `json:"bank,omitempty"` // 题库名称，为空时使用当前激活的题库
+	ID      string          `json:"id,omitempty"`   // 修改和删除时指定题目
+	Item    AnswerItem      `json:"item"`           // 新增和修改时的题目内容
+	Patches []QuestionPatch `json:"patches,omitempty"`
+}
+
+// QuestionResponse HTTP单题操作响应结构
+type QuestionResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message,omitempty"`
+	Item    *AnswerItem  `json:"item,omitempty"`  // 获取、新增、修改的题目
+	Items   []AnswerItem `json:"items,omitempty"` // 批量修改后的题目
+}
+
+// handleGetQuestion 处理HTTP获取题目请求，参数为bank和id
+func handleGetQuestion(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	query := r.URL.Query()
+	item, err := examService.GetQuestion(query.Get("bank"), query.Get("id"))
+	if err != nil {
+		response := QuestionResponse{
+			Success: false,
+			Message: "获取题目失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := QuestionResponse{
+		Success: true,
+		Item:    &item,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleCreateQuestion 处理HTTP新增题目请求
+func handleCreateQuestion(w http.ResponseWriter, r *http.Request) {
+	serveQuestionMutation(w, r, "新增题目", func(e *ExamService, req QuestionRequest) (QuestionResponse, error) {
+		item, err := e.CreateQuestion(req.Bank, req.Item)
+		return QuestionResponse{Item: &item}, err
+	})
+}
+
+// handleUpdateQuestion 处理HTTP修改题目请求
+func handleUpdateQuestion(w http.ResponseWriter, r *http.Request) {
+	serveQuestionMutation(w, r, "修改题目", func(e *ExamService, req QuestionRequest) (QuestionResponse, error) {
+		id := req.ID
+		if id == "" {
+			id = req.Item.ID
+		}
+		item, err := e.UpdateQuestion(req.Bank, id, req.Item)
+		return QuestionResponse{Item: &item}, err
+	})
+}
+
+// handleDeleteQuestion 处理HTTP删除题目请求
+func handleDeleteQuestion(w http.ResponseWriter, r *http.Request) {
+	serveQuestionMutation(w, r, "删除题目", func(e *ExamService, req QuestionRequest) (QuestionResponse, error) {
+		return QuestionResponse{}, e.DeleteQuestion(req.Bank, req.ID)
+	})
+}
+
+// handlePatchQuestions 处理HTTP批量修改题目请求
+func handlePatchQuestions(w http.ResponseWriter, r *http.Request) {
+	serveQuestionMutation(w, r, "批量修改题目", func(e *ExamService, req QuestionRequest) (QuestionResponse, error) {
+		items, err := e.PatchQuestions(req.Bank, req.Patches)
+		return QuestionResponse{Items: items}, err
+	})
+}
+
+// serveQuestionMutation 单题操作类POST请求的公共处理流程
+func serveQuestionMutation(w http.ResponseWriter, r *http.Request, action string, apply func(e *ExamService, req QuestionRequest) (QuestionResponse, error)) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req QuestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response, err := apply(examService, req)
+	if err != nil {
+		response := QuestionResponse{
+			Success: false,
+			Message: action + "失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response.Success = true
+	response.Message = action + "成功"
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQuestionEditsResolveAnswerText(t *testing.T) {
+	useTempAppData(t)
+	e := &ExamService{}
+	if err := e.SetBankAnswers("编辑", []AnswerItem{}); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := e.CreateQuestion("编辑", AnswerItem{Question: "哪种语言有借用检查", Options: []string{"Rust", "Go"}, Answer: []string{"Rust"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(created.AnswerKeys, []string{"A"}) {
+		t.Errorf("答案字母 = %q", created.AnswerKeys)
+	}
+
+	updated, err := e.UpdateQuestion("编辑", created.ID, AnswerItem{Question: "首都", Options: []string{"A. 北京", "B. 上海"}, Answer: []string{"A. 北京"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 调整选项顺序后答案仍是原来的选项文本
+	options := []string{"A. 上海", "B. 北京"}
+	patched, err := e.PatchQuestions("编辑", []QuestionPatch{{ID: updated.ID, Options: &options}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(patched[0].Answer, []string{"B. 北京"}) || !slices.Equal(patched[0].AnswerKeys, []string{"B"}) {
+		t.Errorf("调整选项后答案 = %q %q", patched[0].Answer, patched[0].AnswerKeys)
+	}
+
+	// 修改题目时答案不按选项字母解析
+	if _, err := e.UpdateQuestion("编辑", created.ID, AnswerItem{Question: "首都", Options: []string{"北京", "上海"}, Answer: []string{"A"}}); err == nil {
+		t.Error("答案不是选项文本时应返回错误")
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 标准题型，导入题目时生成的题目类型名称与常见题库文件中的写法一致
+const (
+	QuestionTypeSingle   = "单选题"
+	QuestionTypeMultiple = "多选题"
+	QuestionTypeJudge    = "判断题"
+	QuestionTypeBlank    = "填空题"
+	QuestionTypeShort    = "简答题"
+)
+
+// questionTypes 全部标准题型
+var questionTypes = []string{QuestionTypeSingle, QuestionTypeMultiple, QuestionTypeJudge, QuestionTypeBlank, QuestionTypeShort}
+
+// builtinTypeAliases 内置的题型别名，标准题型 -> 别名列表。
+// "选择题"没有说明单选还是多选，按单选题处理，有多个答案时再视为多选题
+var builtinTypeAliases = map[string][]string{
+	QuestionTypeSingle:   {"单选", "单项选择题", "单项选择", "单选择题", "选择题", "选择", "Single", "Single Choice", "Choice", "Radio"},
+	QuestionTypeMultiple: {"多选", "多项选择题", "多项选择", "多选择题", "不定项选择题", "不定项选择", "不定项", "Multiple", "Multiple Choice", "Multi", "Checkbox"},
+	QuestionTypeJudge:    {"判断", "是非题", "是非", "对错题", "正误题", "True/False", "TrueFalse", "TF", "Judge", "Boolean"},
+	QuestionTypeBlank:    {"填空", "填充题", "Blank", "Fill-in", "Fill in the Blank", "Cloze"},
+	QuestionTypeShort:    {"简答", "问答题", "问答", "论述题", "论述", "名词解释", "Short Answer", "Short", "Essay"},
+}
+
+// isQuestionType 判断是否为标准题型
+func isQuestionType(value string) bool {
+	for _, t := range questionTypes {
+		if value == t {
+			return true
+		}
+	}
+	return false
+}
+
+// typeResolver 将题目中的题型写法对应到标准题型
+type typeResolver struct {
+	lookup map[string]string // 标准化的写法 -> 标准题型
+}
+
+// newTypeResolver 根据内置别名和当前设置中的用户别名建立题型查找表，用户别名优先
+func newTypeResolver() typeResolver {
+	userAliases := currentSettings().TypeAliases
+	lookup := map[string]string{}
+	for _, t := range questionTypes {
+		lookup[normalizeHeader(t)] = t
+		for _, alias := range builtinTypeAliases[t] {
+			lookup[normalizeHeader(alias)] = t
+		}
+	}
+	for _, t := range questionTypes {
+		for _, alias := range userAliases[t] {
+			lookup[normalizeHeader(alias)] = t
+		}
+	}
+	return typeResolver{lookup: lookup}
+}
+
+// label 返回题型写法对应的标准题型，无法识别时返回空字符串
+func (r typeResolver) label(raw string) string {
+	return r.lookup[normalizeHeader(raw)]
+}
+
+// resolve 返回题目的标准题型。没有填写题型时根据选项和答案推断，
+// 单选题有多个答案时视为多选题，无法识别的题型原样返回
+func (r typeResolver) resolve(item AnswerItem) string {
+	raw := strings.TrimSpace(item.Type)
+	if raw == "" {
+		return inferQuestionType(item)
+	}
+	t := r.label(raw)
+	if t == "" {
+		return raw
+	}
+	if t == QuestionTypeSingle && answerCount(item) > 1 {
+		return QuestionTypeMultiple
+	}
+	return t
+}
+
+// labels 将一组题型写法转换为标准题型，无法识别的原样保留
+func (r typeResolver) labels(values []string) []string {
+	resolved := make([]string, 0, len(values))
+	for _, value := range values {
+		if t := r.label(value); t != "" {
+			value = t
+		}
+		resolved = append(resolved, value)
+	}
+	return resolved
+}
+
+// inferQuestionType 根据选项和答案推断没有填写题型的题目
+func inferQuestionType(item AnswerItem) string {
+	switch {
+	case len(item.Options) == 2 && item.Options[0] == judgeTrue && item.Options[1] == judgeFalse:
+		return QuestionTypeJudge
+	case len(item.Options) == 0:
+		return QuestionTypeBlank
+	case answerCount(item) > 1:
+		return QuestionTypeMultiple
+	default:
+		return QuestionTypeSingle
+	}
+}
+
+// answerCount 题目的答案个数，优先使用答案选项字母
+func answerCount(item AnswerItem) int {
+	if len(item.AnswerKeys) > 0 {
+		return len(item.AnswerKeys)
+	}
+	return len(item.Answer)
+}
+
+// QuestionTypeInfo 标准题型及其可识别的别名
+type QuestionTypeInfo struct {
+	Type    string   `json:"type"`
+	Aliases []string `json:"aliases"`
+}
+
+// GetQuestionTypes 获取全部标准题型及其别名，包括内置别名和用户别名
+func (e *ExamService) GetQuestionTypes() []QuestionTypeInfo {
+	userAliases := currentSettings().TypeAliases
+	types := []QuestionTypeInfo{}
+	for _, t := range questionTypes {
+		aliases := append([]string{}, builtinTypeAliases[t]...)
+		aliases = append(aliases, userAliases[t]...)
+		types = append(types, QuestionTypeInfo{Type: t, Aliases: aliases})
+	}
+	return types
+}
+
+// NormalizeQuestionType 返回题型写法对应的标准题型，无法识别时返回错误
+func (e *ExamService) NormalizeQuestionType(raw string) (string, error) {
+	if t := newTypeResolver().label(raw); t != "" {
+		return t, nil
+	}
+	return "", fmt.Errorf("无法识别的题型: %s", raw)
+}
+
+// GetTypeAliases 获取用户自定义的题型别名（标准题型 -> 别名列表）
+func (e *ExamService) GetTypeAliases() map[string][]string {
+	aliases := currentSettings().TypeAliases
+	if aliases == nil {
+		return map[string][]string{}
+	}
+	return aliases
+}
+
+// SetTypeAliases 设置用户自定义的题型别名并保存，替换之前的全部用户别名
+func (e *ExamService) SetTypeAliases(aliases map[string][]string) error {
+	for t := range aliases {
+		if !isQuestionType(t) {
+			return fmt.Errorf("未知题型: %s", t)
+		}
+	}
+
+	cleaned := map[string][]string{}
+	owner := map[string]string{}
+	for _, t := range questionTypes {
+		for _, alias := range aliases[t] {
+			alias = strings.TrimSpace(alias)
+			if normalizeHeader(alias) == "" {
+				continue
+			}
+			if other, ok := owner[normalizeHeader(alias)]; ok && other != t {
+				return fmt.Errorf("别名%s不能同时对应%s和%s", alias, other, t)
+			}
+			owner[normalizeHeader(alias)] = t
+			cleaned[t] = append(cleaned[t], alias)
+		}
+	}
+
+	return updateSettings(func(s *AppSettings) error {
+		s.TypeAliases = cleaned
+		return nil
+	})
+}
+
+// TypeAliasesRequest HTTP设置题型别名请求结构
+type TypeAliasesRequest struct {
+	Aliases map[string][]string `json:"aliases"`
+}
+
+// QuestionTypesResponse HTTP题型设置响应结构
+type QuestionTypesResponse struct {
+	Success bool                `json:"success"`
+	Message string              `json:"message,omitempty"`
+	Types   []QuestionTypeInfo  `json:"types,omitempty"`
+	Aliases map[string][]string `json:"aliases,omitempty"`
+}
+
+// handleGetQuestionTypes 处理HTTP获取标准题型及别名请求
+func handleGetQuestionTypes(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	response := QuestionTypesResponse{
+		Success: true,
+		Types:   examService.GetQuestionTypes(),
+		Aliases: examService.GetTypeAliases(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleSetTypeAliases 处理HTTP设置题型别名请求
+func handleSetTypeAliases(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req TypeAliasesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	if err := examService.SetTypeAliases(req.Aliases); err != nil {
+		response := QuestionTypesResponse{
+			Success: false,
+			Message: "设置题型别名失败: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := QuestionTypesResponse{
+		Success: true,
+		Message: "设置题型别名成功",
+		Types:   examService.GetQuestionTypes(),
+		Aliases: examService.GetTypeAliases(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
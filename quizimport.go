@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 判断题的两个选项
+const (
+	judgeTrue  = "正确"
+	judgeFalse = "错误"
+)
+
+// 题目交换格式
+const (
+	QuizFormatMoodleXML = "moodle" // Moodle XML
+	QuizFormatGIFT      = "gift"   // GIFT纯文本
+)
+
+var (
+	// htmlBreakPattern 匹配会产生换行的HTML标签
+	htmlBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</div>|</li>`)
+	// htmlTagPattern 匹配任意HTML标签
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// htmlToText 将题目中的HTML转换为纯文本：段落和换行标签转为换行，去掉其余标签并还原实体，
+// 每行首尾空白和多余空行一并去掉
+func htmlToText(s string) string {
+	s = htmlBreakPattern.ReplaceAllString(s, "\n")
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := []string{}
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// quizFormatFromPath 根据扩展名判断题目交换格式，.xml为Moodle XML，其余按GIFT处理
+func quizFormatFromPath(path string) string {
+	if strings.ToLower(filepath.Ext(path)) == ".xml" {
+		return QuizFormatMoodleXML
+	}
+	return QuizFormatGIFT
+}
+
+// ParseQuizFile 解析Moodle XML或GIFT文件，format为空时按扩展名判断。
+// 不支持的题型（如问答题、匹配题）记入导入报告
+func (e *ExamService) ParseQuizFile(filePath string, format string) (ImportResult, error) {
+	return e.parseQuizFile(ParseQuizRequest{FilePath: filePath, Format: format})
+}
+
+// parseQuizFile 按请求中的设置解析Moodle XML或GIFT文件并生成导入报告
+func (e *ExamService) parseQuizFile(req ParseQuizRequest) (ImportResult, error) {
+	data, err := os.ReadFile(req.FilePath)
+	if err != nil {
+		return newImportCollector(req.Strict).result, fmt.Errorf("无法打开文件: %v", err)
+	}
+
+	// Moodle导出的文件均为UTF-8，GIFT文件可能由记事本等工具编写，统一按编码设置解码
+	content, usedEncoding, err := decodeText(data, req.Encoding)
+	if err != nil {
+		return newImportCollector(req.Strict).result, err
+	}
+
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = quizFormatFromPath(req.FilePath)
+	}
+
+	var result ImportResult
+	switch format {
+	case QuizFormatMoodleXML, "xml":
+		result, err = parseMoodleXML(content, req.Strict)
+	case QuizFormatGIFT:
+		result, err = parseGIFT(content, req.Strict)
+	default:
+		return newImportCollector(req.Strict).result, fmt.Errorf("不支持的题目格式: %s，仅支持moodle和gift", req.Format)
+	}
+	result.Encoding = usedEncoding
+	result.setSource(req.FilePath)
+	return result, err
+}
+
+// ParseQuizRequest HTTP Moodle XML/GIFT解析请求结构
+type ParseQuizRequest struct {
+	FilePath string `json:"filePath"`
+	Format   string `json:"format,omitempty"`   // moodle或gift，为空时按扩展名判断
+	Encoding string `json:"encoding,omitempty"` // 文件编码，auto或空时自动检测
+	Strict   bool   `json:"strict,omitempty"`   // 严格模式：任意一题有问题即整体失败，默认跳过有问题的题目
+}
+
+// ParseQuizResponse HTTP Moodle XML/GIFT解析响应结构
+type ParseQuizResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message,omitempty"`
+	Encoding string        `json:"encoding,omitempty"` // 实际使用的文件编码
+	Results  []AnswerItem  `json:"results,omitempty"`
+	Report   *ImportReport `json:"report,omitempty"` // 逐题导入报告，含不支持的题型
+}
+
+// handleParseQuiz 处理HTTP Moodle XML/GIFT解析请求
+func handleParseQuiz(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ParseQuizRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	result, err := examService.parseQuizFile(req)
+	if err != nil {
+		response := ParseQuizResponse{
+			Success: false,
+			Message: "题目文件解析失败: " + err.Error(),
+			Report:  &result.Report,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := ParseQuizResponse{
+		Success:  true,
+		Message:  result.Report.Summary(),
+		Encoding: result.Encoding,
+		Results:  result.Items,
+		Report:   &result.Report,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
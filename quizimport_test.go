@@ -0,0 +1,51 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseGIFTWordAnswers(t *testing.T) {
+	content := "::q1:: Which language has a borrow checker? {=Rust ~Go ~Java}\n\n" +
+		"::q2:: Which protocol is connectionless? {~TCP =UDP}\n\n" +
+		"Water is wet. {T}\n"
+	result, err := parseGIFT(content, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 3 {
+		t.Fatalf("导入 %d 道题，期望 3 道: %+v", len(result.Items), result.Report)
+	}
+	want := []struct{ answer, key string }{{"Rust", "A"}, {"UDP", "B"}, {judgeTrue, "A"}}
+	for i, w := range want {
+		item := result.Items[i]
+		if !slices.Equal(item.Answer, []string{w.answer}) || !slices.Equal(item.AnswerKeys, []string{w.key}) || len(item.Warnings) > 0 {
+			t.Errorf("第%d题答案 = %q %q %q", i+1, item.Answer, item.AnswerKeys, item.Warnings)
+		}
+	}
+}
+
+func TestParseMoodleXMLWordAnswers(t *testing.T) {
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="multichoice">
+    <name><text>q1</text></name>
+    <questiontext format="html"><text>Which protocols are connectionless?</text></questiontext>
+    <single>false</single>
+    <answer fraction="0"><text>TCP</text></answer>
+    <answer fraction="50"><text>UDP</text></answer>
+    <answer fraction="50"><text>QUIC</text></answer>
+  </question>
+</quiz>`
+	result, err := parseMoodleXML(content, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("导入 %d 道题，期望 1 道: %+v", len(result.Items), result.Report)
+	}
+	item := result.Items[0]
+	if !slices.Equal(item.Answer, []string{"UDP", "QUIC"}) || !slices.Equal(item.AnswerKeys, []string{"B", "C"}) {
+		t.Errorf("答案 = %q %q", item.Answer, item.AnswerKeys)
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxIndexCandidates 索引筛选后最多保留的候选题目数量
+const maxIndexCandidates = 500
+
+// searchIndex 题库倒排索引
+// 中文和拉丁文字、数字都按单字、二元组和三元组建立倒排表，拉丁文字和数字另按整个单词建立，
+// 这样查询是题目的任意一段（如单个汉字、单词的一部分）时也能命中。
+// 搜索时先用索引筛选出候选题目，再对候选题目逐一计算匹配度
+type searchIndex struct {
+	postings  map[string][]posting // 词项 -> 包含该词项的题目（按下标升序）
+	docLen    []int32              // 每个题目的词项总数，用于BM25长度归一化
+	avgDocLen float64              // 平均词项数
+	size      int                  // 题目总数
+}
+
+// posting 倒排表中的一项
+type posting struct {
+	doc int32 // 题目下标
+	tf  int32 // 词项在该题目中出现的次数
+}
+
+// newSearchIndex 为题目列表建立倒排索引
+func newSearchIndex(answers []AnswerItem) *searchIndex {
+	idx := &searchIndex{
+		postings: make(map[string][]posting),
+		docLen:   make([]int32, len(answers)),
+		size:     len(answers),
+	}
+
+	for i, answer := range answers {
+		terms := answerTerms(answer)
+		idx.docLen[i] = int32(len(terms))
+		for _, term := range terms {
+			list := idx.postings[term]
+			// 题目按顺序处理，同一题目重复出现的词项只累加次数
+			if n := len(list); n > 0 && list[n-1].doc == int32(i) {
+				list[n-1].tf++
+				continue
+			}
+			idx.postings[term] = append(list, posting{doc: int32(i), tf: 1})
+		}
+	}
+
+	idx.updateAvgDocLen()
+	return idx
+}
+
+// answerTerms 提取一道题的全部词项，题目、选项、答案分别切分，词项不跨字段
+func answerTerms(answer AnswerItem) []string {
+	e := &ExamService{}
+	fields := make([]string, 0, 1+len(answer.Options)+len(answer.Answer))
+	fields = append(fields, answer.Question)
+	fields = append(fields, answer.Options...)
+	fields = append(fields, answer.Answer...)
+
+	terms := []string{}
+	for _, field := range fields {
+		terms = append(terms, e.indexTerms(field)...)
+	}
+	return terms
+}
+
+// updateAvgDocLen 根据每个题目的词项数重新计算平均词项数
+func (idx *searchIndex) updateAvgDocLen() {
+	idx.avgDocLen = 0
+	if idx.size == 0 {
+		return
+	}
+	totalLen := 0
+	for _, n := range idx.docLen {
+		totalLen += int(n)
+	}
+	idx.avgDocLen = float64(totalLen) / float64(idx.size)
+}
+
+// update 返回修改部分题目后的新索引，原索引保持不变。
+// old为修改前的题目，answers为修改后的题目（可在末尾追加），changed为修改或追加的题目下标。
+// 只重新切分变化的题目，没有涉及的倒排表与原索引共用
+func (idx *searchIndex) update(old, answers []AnswerItem, changed []int) *searchIndex {
+	next := &searchIndex{
+		postings: make(map[string][]posting, len(idx.postings)),
+		docLen:   make([]int32, len(answers)),
+		size:     len(answers),
+	}
+	for term, list := range idx.postings {
+		next.postings[term] = list
+	}
+	copy(next.docLen, idx.docLen)
+
+	// 先去掉变化题目原有的词项，再加入新的词项
+	copied := map[string]bool{}
+	writable := func(term string) []posting {
+		list := next.postings[term]
+		if !copied[term] {
+			list = append(make([]posting, 0, len(list)+1), list...)
+			copied[term] = true
+		}
+		return list
+	}
+	for _, i := range changed {
+		if i >= len(old) {
+			continue
+		}
+		for term := range termCounts(answerTerms(old[i])) {
+			list := writable(term)
+			at := sort.Search(len(list), func(k int) bool { return list[k].doc >= int32(i) })
+			if at < len(list) && list[at].doc == int32(i) {
+				list = append(list[:at], list[at+1:]...)
+			}
+			if len(list) == 0 {
+				delete(next.postings, term)
+				continue
+			}
+			next.postings[term] = list
+		}
+	}
+	for _, i := range changed {
+		terms := answerTerms(answers[i])
+		next.docLen[i] = int32(len(terms))
+		for term, tf := range termCounts(terms) {
+			list := writable(term)
+			at := sort.Search(len(list), func(k int) bool { return list[k].doc >= int32(i) })
+			list = append(list, posting{})
+			copy(list[at+1:], list[at:])
+			list[at] = posting{doc: int32(i), tf: tf}
+			next.postings[term] = list
+		}
+	}
+
+	next.updateAvgDocLen()
+	return next
+}
+
+// without 返回删除部分题目后的新索引，原索引保持不变。
+// 其余题目的下标依次前移，不需要重新切分
+func (idx *searchIndex) without(removed map[int]bool) *searchIndex {
+	// shift[i]为原下标i之前被删除的题目数
+	shift := make([]int32, idx.size+1)
+	for i := 0; i < idx.size; i++ {
+		shift[i+1] = shift[i]
+		if removed[i] {
+			shift[i+1]++
+		}
+	}
+
+	next := &searchIndex{
+		postings: make(map[string][]posting, len(idx.postings)),
+		docLen:   make([]int32, 0, idx.size),
+		size:     idx.size - int(shift[idx.size]),
+	}
+	for i, n := range idx.docLen {
+		if !removed[i] {
+			next.docLen = append(next.docLen, n)
+		}
+	}
+	first := idx.size
+	for i := range removed {
+		if i < first {
+			first = i
+		}
+	}
+	for term, list := range idx.postings {
+		// 全部位于第一个删除的题目之前的倒排表不受影响
+		if len(list) == 0 || int(list[len(list)-1].doc) < first {
+			next.postings[term] = list
+			continue
+		}
+		moved := make([]posting, 0, len(list))
+		for _, p := range list {
+			if !removed[int(p.doc)] {
+				moved = append(moved, posting{doc: p.doc - shift[p.doc], tf: p.tf})
+			}
+		}
+		if len(moved) > 0 {
+			next.postings[term] = moved
+		}
+	}
+
+	next.updateAvgDocLen()
+	return next
+}
+
+// termCounts 统计每个词项出现的次数
+func termCounts(terms []string) map[string]int32 {
+	counts := make(map[string]int32, len(terms))
+	for _, term := range terms {
+		counts[term]++
+	}
+	return counts
+}
+
+// indexTerms 提取文本的索引词项，与搜索使用相同的标准化方式。
+// 连续的汉字、连续的字母和数字分别切分为单字、二元组和三元组，
+// 长度超过3的单词另外整体作为一个词项
+func (e *ExamService) indexTerms(text string) []string {
+	normalized := strings.ToLower(e.normalizeText(text))
+
+	terms := []string{}
+	han := []rune{}
+	word := []rune{}
+
+	ngrams := func(run []rune) {
+		for size := 1; size <= 3; size++ {
+			for i := 0; i+size <= len(run); i++ {
+				terms = append(terms, string(run[i:i+size]))
+			}
+		}
+	}
+	flushHan := func() {
+		ngrams(han)
+		han = han[:0]
+	}
+	flushWord := func() {
+		ngrams(word)
+		if len(word) > 3 {
+			terms = append(terms, string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range normalized {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+
+	return terms
+}
+
+// candidates 返回与查询词项有交集的题目下标（升序）。
+// 命中的题目过多时按命中词项数保留前maxIndexCandidates个，完整包含查询的题目命中全部词项，总是排在前面；
+// 查询没有可用词项时返回false，调用方应退回全量扫描
+func (idx *searchIndex) candidates(queryTerms []string) ([]int, bool) {
+	return idx.candidatesWithin(queryTerms, nil)
+}
+
+// candidatesWithin 与candidates相同，但只返回allowed中为true的题目，allowed为nil时不限。
+// 没有题目命中时同样返回false：形近字等模糊匹配可能在没有共同字符时得分，此时退回全量扫描
+func (idx *searchIndex) candidatesWithin(queryTerms []string, allowed []bool) ([]int, bool) {
+	result, ok := idx.shortlist(queryTerms, allowed, 1)
+	return result, ok && len(result) > 0
+}
+
+// candidatesSharing 与candidates相同，但只返回至少命中minShare比例的不同查询词项的题目，
+// 用于查找近似重复的题目，共有词项少的题目不可能足够相似
+func (idx *searchIndex) candidatesSharing(queryTerms []string, minShare float64) ([]int, bool) {
+	distinct := map[string]bool{}
+	for _, term := range queryTerms {
+		distinct[term] = true
+	}
+	minHits := int(math.Ceil(minShare * float64(len(distinct))))
+	if minHits < 1 {
+		minHits = 1
+	}
+	return idx.shortlist(queryTerms, nil, int32(minHits))
+}
+
+// shortlist 返回命中至少minHits个不同查询词项且在allowed范围内的题目下标（升序），
+// 超过maxIndexCandidates个时保留命中词项多的
+func (idx *searchIndex) shortlist(queryTerms []string, allowed []bool, minHits int32) ([]int, bool) {
+	if len(queryTerms) == 0 {
+		return nil, false
+	}
+
+	hits := make([]int32, idx.size)
+	seen := make(map[string]bool, len(queryTerms))
+	for _, term := range queryTerms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		for _, p := range idx.postings[term] {
+			hits[p.doc]++
+		}
+	}
+
+	result := []int{}
+	for i, count := range hits {
+		if count >= minHits && (allowed == nil || allowed[i]) {
+			result = append(result, i)
+		}
+	}
+
+	if len(result) > maxIndexCandidates {
+		sort.SliceStable(result, func(a, b int) bool {
+			return hits[result[a]] > hits[result[b]]
+		})
+		result = result[:maxIndexCandidates]
+		sort.Ints(result)
+	}
+
+	return result, true
+}
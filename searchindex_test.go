@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// benchmarkWords 生成题库时使用的词语
+var benchmarkWords = []string{
+	"光速", "水分子", "细胞膜", "牛顿定律", "化学反应", "电磁感应", "地球自转", "声波", "光合作用", "氧化还原",
+	"遗传基因", "万有引力", "热力学", "原子核", "催化剂", "生态系统", "板块运动", "大气压", "摩擦力", "电阻",
+}
+
+// benchmarkBank 生成指定题数的题库，同一随机种子生成的题库相同
+func benchmarkBank(n int) []AnswerItem {
+	r := rand.New(rand.NewSource(1))
+	items := make([]AnswerItem, 0, n)
+	for i := 0; i < n; i++ {
+		words := make([]string, 4)
+		for j := range words {
+			words[j] = benchmarkWords[r.Intn(len(benchmarkWords))]
+		}
+		items = append(items, AnswerItem{
+			Question: fmt.Sprintf("第%d题 下列关于%s的说法正确的是", i, strings.Join(words, "和")),
+			Options:  []string{"A. " + words[0], "B. " + words[1], "C. " + words[2], "D. " + words[3]},
+			Answer:   []string{"A. " + words[0]},
+		})
+	}
+	return items
+}
+
+// TestIndexedSearchMatchesLinear 使用索引筛选候选后，中等准确度以上的结果应与全量扫描相同。
+// 更低的分数来自与查询没有共同字符的编辑距离匹配，索引不保证找到
+func TestIndexedSearchMatchesLinear(t *testing.T) {
+	e := &ExamService{}
+	answers := []AnswerItem{
+		{Question: "光速是多少", Options: []string{"A. 很快", "B. 很慢"}, Answer: []string{"A. 很快"}},
+		{Question: "光", Answer: []string{"电磁波"}},
+		{Question: "速度的单位", Answer: []string{"米每秒"}},
+		{Question: "哪种语言运行在浏览器中", Options: []string{"A. JavaScript", "B. Python"}, Answer: []string{"A. JavaScript"}},
+		{Question: "Python使用什么表示代码块", Answer: []string{"缩进"}},
+		{Question: "水的化学式", Answer: []string{"H2O"}},
+	}
+	answers = append(answers, benchmarkBank(200)...)
+	cfg := DefaultMatcherConfig()
+
+	linear := searchTarget{answers: answers}
+	indexed := searchTarget{answers: answers, index: newSearchIndex(answers)}
+	for _, q := range []string{"光", "速", "光速", "javas", "pyth", "h2", "电磁感应"} {
+		query := strings.ToLower(e.normalizeText(q))
+		queryTerms := e.indexTerms(query)
+		want := matchedResults(e.scoreTargetHeuristic(linear, query, queryTerms, cfg, false), cfg)
+		got := matchedResults(e.scoreTargetHeuristic(indexed, query, queryTerms, cfg, false), cfg)
+		if len(want) == 0 {
+			t.Errorf("%q: 全量扫描没有结果", q)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: 索引得到 %d 个结果，全量扫描 %d 个", q, len(got), len(want))
+		}
+	}
+}
+
+// matchedResults 取出达到中等准确度的结果，按题目记录分数
+func matchedResults(results []SearchResult, cfg MatcherConfig) map[string]float64 {
+	matched := map[string]float64{}
+	for _, result := range results {
+		if result.Score >= cfg.MediumThreshold {
+			matched[result.Item.Question] = result.Score
+		}
+	}
+	return matched
+}
+
+// BenchmarkSearch 比较2万道题的题库全量扫描与使用倒排索引筛选候选的耗时
+func BenchmarkSearch(b *testing.B) {
+	e := &ExamService{}
+	answers := benchmarkBank(20000)
+	cfg := DefaultMatcherConfig()
+	query := strings.ToLower(e.normalizeText("第12345题 下列关于光速的说法"))
+	queryTerms := e.indexTerms(query)
+
+	targets := []struct {
+		name   string
+		target searchTarget
+	}{
+		{"linear", searchTarget{answers: answers}},
+		{"index", searchTarget{answers: answers, index: newSearchIndex(answers)}},
+	}
+	for _, tt := range targets {
+		b.Run(tt.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				e.scoreTargetHeuristic(tt.target, query, queryTerms, cfg, false)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// SegmentPatterns 将逐行文本切分为题目时使用的正则表达式，为空的字段使用默认规则
+type SegmentPatterns struct {
+	Section      string `json:"section"`      // 题型标题行，如"一、单选题"，第一个捕获组为题型
+	Question     string `json:"question"`     // 题目开头的题号，匹配部分会从题目中去掉；名为type的捕获组为该题的题型；分隔符后紧跟数字时视为小数，不是新题目
+	Option       string `json:"option"`       // 选项标号，需出现在行首，同一行中可以有多个选项
+	Answer       string `json:"answer"`       // 答案行，第一个捕获组为答案
+	Explanation  string `json:"explanation"`  // 解析行，第一个捕获组为解析
+	InlineAnswer string `json:"inlineAnswer"` // 写在题目括号中的答案，如"（B）"，没有答案行时使用，第一个捕获组为答案
+}
+
+// defaultSegmentPatterns 默认切分规则，适用于"1. 题目 / A. 选项 / 答案：B"这类常见写法
+var defaultSegmentPatterns = SegmentPatterns{
+	Section:      `^\s*[一二三四五六七八九十]+\s*[、.．]\s*([^\s（(]*题)`,
+	Question:     `^\s*(?:第\s*\d+\s*题\s*[.．、:：]?|\d+\s*[.．、)）]|[(（]\s*\d+\s*[)）])\s*`,
+	Option:       `(?:^|[\s　]+)[(（]?\s*[A-Ha-h]\s*[.．、:：)）]\s*`,
+	Answer:       `^\s*[【\[]?\s*(?:正确答案|参考答案|标准答案|答案)\s*[】\]]?\s*[:：]?\s*(.*)$`,
+	Explanation:  `^\s*[【\[]?\s*(?:答案解析|试题解析|解析)\s*[】\]]?\s*[:：]?\s*(.*)$`,
+	InlineAnswer: `[（(]\s*([A-Ha-h]{1,8}|√|×)\s*[）)]`,
+}
+
+// SegmentPreset 内置的切分规则预设，对应常见的题库排版
+type SegmentPreset struct {
+	Name     string          `json:"name"`
+	Label    string          `json:"label"` // 显示名称及适用的排版
+	Patterns SegmentPatterns `json:"patterns"`
+}
+
+// defaultPreviewCount 预览切分结果时默认展示的题目数量
+const defaultPreviewCount = 10
+
+// defaultSegmentPreset 未指定预设时使用的预设
+const defaultSegmentPreset = "standard"
+
+// segmentPresets 内置的切分规则预设，预设中为空的字段使用默认规则
+var segmentPresets = []SegmentPreset{
+	{
+		Name:     defaultSegmentPreset,
+		Label:    "常见格式：一、单选题 / 1. 题目 / A. 选项 / 答案：B，答案也可写在题目括号中",
+		Patterns: defaultSegmentPatterns,
+	},
+	{
+		Name:  "inline-type",
+		Label: "题号后标注题型：1.【单选题】题目 / A. 选项 / 答案：B",
+		Patterns: SegmentPatterns{
+			Question: `^\s*(?:第\s*\d+\s*题|\d+\s*[.．、)）]|[(（]\s*\d+\s*[)）])\s*(?:[【\[](?P<type>[^】\]]+)[】\]]\s*)?`,
+		},
+	},
+	{
+		Name:  "qa",
+		Label: "问答格式：问：题目 / 答：答案，题目前可以有题号",
+		Patterns: SegmentPatterns{
+			Question: `^\s*(?:\d+\s*[.．、)）]\s*)?(?:问|问题|题目)\s*[:：]\s*`,
+			// 问答题一般没有选项，选项标号只识别"A."、"A、"写法，避免把"答："之外的冒号行当作选项
+			Option: `(?:^|[\s　]+)[(（]?\s*[A-Ha-h]\s*[.．、)）]\s*`,
+			Answer: `^\s*(?:答|答案|回答)\s*[:：]\s*(.*)$`,
+		},
+	},
+}
+
+// findSegmentPreset 按名称查找内置预设
+func findSegmentPreset(name string) (SegmentPreset, error) {
+	if strings.TrimSpace(name) == "" {
+		name = defaultSegmentPreset
+	}
+	for _, preset := range segmentPresets {
+		if preset.Name == name {
+			return preset, nil
+		}
+	}
+	names := []string{}
+	for _, preset := range segmentPresets {
+		names = append(names, preset.Name)
+	}
+	return SegmentPreset{}, fmt.Errorf("切分规则预设不存在: %s，可选预设: %s", name, strings.Join(names, ", "))
+}
+
+// resolveSegmentPatterns 以预设为基础，用patterns中不为空的字段覆盖后编译
+func resolveSegmentPatterns(presetName string, patterns SegmentPatterns) (*segmentMatchers, error) {
+	preset, err := findSegmentPreset(presetName)
+	if err != nil {
+		return nil, err
+	}
+	merged := preset.Patterns
+	override := func(dst *string, value string) {
+		if strings.TrimSpace(value) != "" {
+			*dst = value
+		}
+	}
+	override(&merged.Section, patterns.Section)
+	override(&merged.Question, patterns.Question)
+	override(&merged.Option, patterns.Option)
+	override(&merged.Answer, patterns.Answer)
+	override(&merged.Explanation, patterns.Explanation)
+	override(&merged.InlineAnswer, patterns.InlineAnswer)
+	return compileSegmentPatterns(merged)
+}
+
+// ListSegmentPresets 列出内置的切分规则预设，预设中为空的字段已填入默认规则
+func (e *ExamService) ListSegmentPresets() []SegmentPreset {
+	presets := []SegmentPreset{}
+	for _, preset := range segmentPresets {
+		fill := func(value *string, fallback string) {
+			if *value == "" {
+				*value = fallback
+			}
+		}
+		fill(&preset.Patterns.Section, defaultSegmentPatterns.Section)
+		fill(&preset.Patterns.Question, defaultSegmentPatterns.Question)
+		fill(&preset.Patterns.Option, defaultSegmentPatterns.Option)
+		fill(&preset.Patterns.Answer, defaultSegmentPatterns.Answer)
+		fill(&preset.Patterns.Explanation, defaultSegmentPatterns.Explanation)
+		fill(&preset.Patterns.InlineAnswer, defaultSegmentPatterns.InlineAnswer)
+		presets = append(presets, preset)
+	}
+	return presets
+}
+
+// GetDefaultSegmentPatterns 获取默认的题目切分规则，供前端在此基础上修改
+func (e *ExamService) GetDefaultSegmentPatterns() SegmentPatterns {
+	return defaultSegmentPatterns
+}
+
+// 判断题答案的常见写法
+var (
+	judgeTrueAnswers  = []string{"正确", "对", "√", "✓", "是", "T", "TRUE", "Y"}
+	judgeFalseAnswers = []string{"错误", "错", "×", "✗", "否", "F", "FALSE", "N"}
+)
+
+// segmentMatchers 编译后的切分规则
+type segmentMatchers struct {
+	section, question, option, answer, explanation, inlineAnswer *regexp.Regexp
+}
+
+// compileSegmentPatterns 编译切分规则，为空的字段使用默认规则
+func compileSegmentPatterns(patterns SegmentPatterns) (*segmentMatchers, error) {
+	compile := func(name string, pattern string, fallback string, needGroup bool) (*regexp.Regexp, error) {
+		if strings.TrimSpace(pattern) == "" {
+			pattern = fallback
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s规则无效: %v", name, err)
+		}
+		if needGroup && re.NumSubexp() < 1 {
+			return nil, fmt.Errorf("%s规则需要一个捕获组: %s", name, pattern)
+		}
+		return re, nil
+	}
+
+	m := &segmentMatchers{}
+	var err error
+	if m.section, err = compile("题型", patterns.Section, defaultSegmentPatterns.Section, true); err != nil {
+		return nil, err
+	}
+	if m.question, err = compile("题号", patterns.Question, defaultSegmentPatterns.Question, false); err != nil {
+		return nil, err
+	}
+	if m.option, err = compile("选项", patterns.Option, defaultSegmentPatterns.Option, false); err != nil {
+		return nil, err
+	}
+	if m.answer, err = compile("答案", patterns.Answer, defaultSegmentPatterns.Answer, true); err != nil {
+		return nil, err
+	}
+	if m.explanation, err = compile("解析", patterns.Explanation, defaultSegmentPatterns.Explanation, true); err != nil {
+		return nil, err
+	}
+	if m.inlineAnswer, err = compile("括号答案", patterns.InlineAnswer, defaultSegmentPatterns.InlineAnswer, true); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParsedQuestion 切分出的一道题及其原始文本，供预览时对照
+type ParsedQuestion struct {
+	Line   int        `json:"line"`            // 题目开始的行号，docx为段落序号
+	Source []string   `json:"source"`          // 该题对应的原始文本行
+	Item   AnswerItem `json:"item"`            // 解析结果
+	Issue  string     `json:"issue,omitempty"` // 不能导入的原因
+}
+
+// questionDraft 切分过程中正在收集的一道题
+type questionDraft struct {
+	line        int
+	source      []string
+	section     string
+	question    string
+	options     []string
+	answer      string
+	hasAnswer   bool
+	explanation string
+	last        string // 最后写入的部分，续行追加到这里
+}
+
+// segmentQuestions 按切分规则将逐行文本切分为题目。
+// 题号行开始一道新题，之后的选项行、答案行、解析行归入这道题，其余的行视为上一部分的续行；
+// 第一道题之前的标题、说明等文字被忽略
+func segmentQuestions(lines []string, m *segmentMatchers) []ParsedQuestion {
+	parsed := []ParsedQuestion{}
+	section := ""
+	var draft *questionDraft
+	finish := func() {
+		if draft != nil {
+			parsed = append(parsed, draft.build(m))
+			draft = nil
+		}
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		if match := m.section.FindStringSubmatch(line); match != nil {
+			finish()
+			section = strings.TrimSpace(match[1])
+			continue
+		}
+		if loc := m.question.FindStringSubmatchIndex(line); loc != nil && loc[0] == 0 && loc[1] > 0 && !decimalNumberAt(line, loc[1]) {
+			finish()
+			draft = &questionDraft{line: i + 1, section: section, question: strings.TrimSpace(line[loc[1]:]), last: "question"}
+			draft.source = append(draft.source, line)
+			// 题号中标注的题型优先于题型标题行
+			if group := m.question.SubexpIndex("type"); group > 0 && loc[2*group] >= 0 {
+				if questionType := strings.TrimSpace(line[loc[2*group]:loc[2*group+1]]); questionType != "" {
+					draft.section = questionType
+				}
+			}
+			continue
+		}
+		if draft == nil {
+			continue
+		}
+		draft.source = append(draft.source, line)
+
+		// 先识别解析行："答案解析：……"同时符合答案行的写法，不能当作答案
+		if match := m.explanation.FindStringSubmatch(line); match != nil {
+			draft.explanation, draft.last = strings.TrimSpace(match[1]), "explanation"
+			continue
+		}
+		if match := m.answer.FindStringSubmatch(line); match != nil {
+			draft.answer, draft.hasAnswer, draft.last = strings.TrimSpace(match[1]), true, "answer"
+			continue
+		}
+		if locs := m.option.FindAllStringIndex(line, -1); len(locs) > 0 && locs[0][0] == 0 {
+			// 同一行中的多个选项在每个标号处拆开
+			for j, loc := range locs {
+				end := len(line)
+				if j+1 < len(locs) {
+					end = locs[j+1][0]
+				}
+				draft.options = append(draft.options, strings.TrimSpace(line[loc[0]:end]))
+			}
+			draft.last = "option"
+			continue
+		}
+
+		switch draft.last {
+		case "question":
+			draft.question += "\n" + line
+		case "option":
+			draft.options[len(draft.options)-1] += " " + line
+		case "answer":
+			draft.answer += "\n" + line
+		case "explanation":
+			draft.explanation += "\n" + line
+		}
+	}
+	finish()
+	return parsed
+}
+
+// build 将收集的内容转换为答案项
+func (d *questionDraft) build(m *segmentMatchers) ParsedQuestion {
+	item := AnswerItem{
+		Question:    d.question,
+		Options:     d.options,
+		Answer:      []string{},
+		Explanation: d.explanation,
+	}
+	if item.Options == nil {
+		item.Options = []string{}
+	}
+
+	// 没有答案行时，尝试题目括号中的答案，并将括号中的答案去掉
+	answer := d.answer
+	if !d.hasAnswer {
+		if loc := m.inlineAnswer.FindStringSubmatchIndex(item.Question); loc != nil {
+			answer = item.Question[loc[2]:loc[3]]
+			item.Question = item.Question[:loc[2]] + "  " + item.Question[loc[3]:]
+		}
+	}
+
+	// 没有选项的判断题统一为"正确"、"错误"两个选项
+	judge := ""
+	if len(item.Options) == 0 {
+		judge = judgeAnswer(answer)
+	}
+	switch {
+	case judge != "":
+		item.Options = []string{judgeTrue, judgeFalse}
+		item.Answer = []string{judge}
+	case answer != "":
+		item.Answer = []string{answer}
+	}
+	item = resolveAnswerKeys(item)
+
+	item.Type = sectionQuestionType(d.section)
+	if item.Type == "" {
+		switch {
+		case judge != "":
+			item.Type = QuestionTypeJudge
+		case len(item.Options) == 0:
+			item.Type = QuestionTypeBlank
+		case len(item.AnswerKeys) > 1:
+			item.Type = QuestionTypeMultiple
+		default:
+			item.Type = QuestionTypeSingle
+		}
+	}
+
+	return ParsedQuestion{Line: d.line, Source: d.source, Item: item, Issue: validateAnswerItem(item)}
+}
+
+// decimalNumberAt 判断题号匹配到end为止的部分是否其实是小数或编号的一部分，如"3.14是圆周率"、"1、2两项"：
+// 题号的分隔符后紧跟数字。正则表达式不支持向后断言，而匹配部分会从题目中去掉，因此在匹配后检查
+func decimalNumberAt(line string, end int) bool {
+	if end >= len(line) || line[end] < '0' || line[end] > '9' {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(line[:end])
+	return strings.ContainsRune(".．、)）", last)
+}
+
+// judgeAnswer 识别判断题答案的常见写法，返回"正确"或"错误"，不是判断题答案时返回空字符串
+func judgeAnswer(answer string) string {
+	answer = strings.ToUpper(strings.TrimSpace(answer))
+	for _, value := range judgeTrueAnswers {
+		if answer == value {
+			return judgeTrue
+		}
+	}
+	for _, value := range judgeFalseAnswers {
+		if answer == value {
+			return judgeFalse
+		}
+	}
+	return ""
+}
+
+// sectionQuestionType 根据题型标题确定题目类型，如"单项选择题"为单选题，无法识别时原样返回
+func sectionQuestionType(section string) string {
+	switch {
+	case section == "":
+		return ""
+	case strings.Contains(section, "多选"), strings.Contains(section, "多项"):
+		return QuestionTypeMultiple
+	case strings.Contains(section, "单选"), strings.Contains(section, "单项"):
+		return QuestionTypeSingle
+	case strings.Contains(section, "判断"):
+		return QuestionTypeJudge
+	case strings.Contains(section, "填空"):
+		return QuestionTypeBlank
+	default:
+		return section
+	}
+}
+
+// collectParsedQuestions 将切分结果加入导入结果，有问题的题目记入报告
+func collectParsedQuestions(parsed []ParsedQuestion, strict bool) (ImportResult, error) {
+	collector := newImportCollector(strict)
+	for _, question := range parsed {
+		collector.add(question.Line, question.Item)
+	}
+	return collector.finish()
+}
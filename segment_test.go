@@ -0,0 +1,64 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSegmentQuestionsDecimalContinuation(t *testing.T) {
+	lines := []string{
+		"1. 关于圆周率，下列说法正确的是",
+		"3.14是圆周率的近似值，那么",
+		"A. 圆周率是无理数",
+		"B. 圆周率等于3",
+		"答案：A",
+		"2.【判断题】水是液体",
+		"答案：正确",
+	}
+	for _, preset := range []string{"standard", "inline-type"} {
+		t.Run(preset, func(t *testing.T) {
+			m, err := resolveSegmentPatterns(preset, SegmentPatterns{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed := segmentQuestions(lines, m)
+			if len(parsed) != 2 {
+				t.Fatalf("切分出 %d 道题，期望 2 道: %+v", len(parsed), parsed)
+			}
+			first := parsed[0]
+			if first.Issue != "" || !strings.Contains(first.Item.Question, "3.14是圆周率") {
+				t.Errorf("第1题 = %q，问题: %s", first.Item.Question, first.Issue)
+			}
+			if !slices.Equal(first.Item.AnswerKeys, []string{"A"}) {
+				t.Errorf("第1题答案 = %q %q", first.Item.Answer, first.Item.AnswerKeys)
+			}
+		})
+	}
+}
+
+func TestSegmentQuestionsAnswerExplanation(t *testing.T) {
+	lines := []string{
+		"1. 下列属于水果的是",
+		"A. 苹果",
+		"B. 白菜",
+		"答案：A",
+		"答案解析：苹果是水果",
+	}
+	for _, preset := range []string{"standard", "inline-type"} {
+		t.Run(preset, func(t *testing.T) {
+			m, err := resolveSegmentPatterns(preset, SegmentPatterns{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed := segmentQuestions(lines, m)
+			if len(parsed) != 1 || parsed[0].Issue != "" {
+				t.Fatalf("切分结果: %+v", parsed)
+			}
+			item := parsed[0].Item
+			if !slices.Equal(item.AnswerKeys, []string{"A"}) || item.Explanation != "苹果是水果" {
+				t.Errorf("答案 = %q %q，解析 = %q", item.Answer, item.AnswerKeys, item.Explanation)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// SeparatorAuto 分隔符取该值时自动检测
+const SeparatorAuto = "auto"
+
+// delimiterCandidates 自动检测时尝试的CSV字段分隔符
+var delimiterCandidates = []rune{',', '\t', ';'}
+
+// optionSeparatorCandidates 自动推断时尝试的选项分隔符，得分相同时靠前的优先
+var optionSeparatorCandidates = []string{"\n", "|", ";", "；", ",", "，", "\t", " "}
+
+// separatorSampleSize 推断分隔符时最多检查的行数
+const separatorSampleSize = 50
+
+// DetectedSeparators 导入时实际使用的分隔符，均为可直接回填到请求中的转义形式（如"\n"写作"\\n"）
+type DetectedSeparators struct {
+	Delimiter       string `json:"delimiter,omitempty"` // CSV字段分隔符，Excel导入时为空
+	OptionSeparator string `json:"optionSeparator"`
+	AnswerSeparator string `json:"answerSeparator"`
+}
+
+// isAutoSeparator 判断分隔符设置是否要求自动检测
+func isAutoSeparator(separator string) bool {
+	return strings.EqualFold(strings.TrimSpace(separator), SeparatorAuto)
+}
+
+// escapeSeparator 将分隔符转换为parseSeparator可识别的转义形式
+func (e *ExamService) escapeSeparator(separator string) string {
+	switch separator {
+	case "\n":
+		return "\\n"
+	case "\t":
+		return "\\t"
+	case "\r":
+		return "\\r"
+	case " ":
+		return "\\s"
+	default:
+		return separator
+	}
+}
+
+// resolveDelimiter 确定CSV字段分隔符：为空或auto时根据文件内容检测，否则必须是单个字符
+func (e *ExamService) resolveDelimiter(delimiter string, content string) (rune, error) {
+	if delimiter == "" || isAutoSeparator(delimiter) {
+		return detectDelimiter(content), nil
+	}
+	sep := e.parseSeparator(delimiter)
+	if utf8.RuneCountInString(sep) != 1 {
+		return 0, fmt.Errorf("字段分隔符必须是单个字符: %s", delimiter)
+	}
+	r, _ := utf8.DecodeRuneInString(sep)
+	return r, nil
+}
+
+// detectDelimiter 检测CSV字段分隔符：用各候选分隔符解析文件开头的若干行，
+// 选择标题行至少有两列且与标题列数一致的行最多的一个，都不满足时使用逗号
+func detectDelimiter(content string) rune {
+	best, bestScore := ',', -1
+	for _, candidate := range delimiterCandidates {
+		reader := csv.NewReader(strings.NewReader(content))
+		reader.Comma = candidate
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+
+		headers, err := reader.Read()
+		if err != nil || len(headers) < 2 {
+			continue
+		}
+		score := 0
+		for i := 0; i < separatorSampleSize; i++ {
+			record, err := reader.Read()
+			if err != nil {
+				break
+			}
+			if len(record) == len(headers) {
+				score++
+			}
+		}
+		// 一致的行数相同时，列数多的更可能是正确的分隔符
+		score = score*100 + len(headers)
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// inferOptionSeparator 根据选项列的内容推断选项分隔符：
+// 对每个候选分隔符，统计拆分后至少有两个选项且每个选项都以字母标号开头（如"a：..."、"A. ..."）的单元格数量，
+// 选择数量最多的一个。没有带标号的选项时，单元格含换行则按换行拆分，否则返回空字符串（整格作为一个选项）
+func inferOptionSeparator(cells []string) string {
+	best, bestScore := "", 0
+	for _, candidate := range optionSeparatorCandidates {
+		score := 0
+		for _, cell := range cells {
+			if parts := splitOptionCell(cell, candidate); len(parts) >= 2 && labeledOptionCount(parts) == len(parts) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == "" {
+		for _, cell := range cells {
+			if strings.Contains(cell, "\n") {
+				return "\n"
+			}
+		}
+	}
+	return best
+}
+
+// splitOptionCell 按分隔符拆分选项单元格，去掉空白和空的部分
+func splitOptionCell(cell string, separator string) []string {
+	parts := []string{}
+	for _, part := range strings.Split(cell, separator) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// labeledOptionCount 统计以字母标号开头的选项数量
+func labeledOptionCount(parts []string) int {
+	count := 0
+	for _, part := range parts {
+		if label, _ := optionLabel(part); label != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// splitOptions 按分隔符拆分一行的选项。rowFallback为true（分隔符是按整个文件推断的）时，
+// 若拆分后带标号的选项不足两个，改为按该行自身推断的分隔符拆分，
+// 以处理大部分行按换行分隔、个别行写成"A. …,B. …"的文件
+func splitOptions(cell string, separator string, rowFallback bool) []string {
+	options := []string{cell}
+	if separator != "" {
+		options = strings.Split(cell, separator)
+	}
+	if !rowFallback || labeledOptionCount(options) >= 2 {
+		return options
+	}
+	if own := inferOptionSeparator([]string{cell}); own != "" && own != separator {
+		return strings.Split(cell, own)
+	}
+	return options
+}
+
+// resolveAnswerSeparators 确定选项和答案分隔符，设置为auto时根据选项列推断。
+// 答案分隔符为auto时与选项分隔符相同：字母答案如"AB"、"a,c"无论是否拆分都能对应到选项。
+// 返回值为转义形式，可直接传给parseAnswerRecord；inferred表示选项分隔符是推断的，解析时允许逐行改用其他分隔符
+func (e *ExamService) resolveAnswerSeparators(optionSeparator string, answerSeparator string, records [][]string, optionColumn int) (string, string, bool) {
+	inferred := isAutoSeparator(optionSeparator)
+	if inferred {
+		cells := []string{}
+		for _, record := range records {
+			if len(cells) >= separatorSampleSize {
+				break
+			}
+			if cell := cellAt(record, optionColumn); strings.TrimSpace(cell) != "" {
+				cells = append(cells, cell)
+			}
+		}
+		optionSeparator = e.escapeSeparator(inferOptionSeparator(cells))
+	}
+	if isAutoSeparator(answerSeparator) {
+		answerSeparator = optionSeparator
+	}
+	return optionSeparator, answerSeparator, inferred
+}
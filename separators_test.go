@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseAnswerRecordRowSeparatorFallback(t *testing.T) {
+	e := &ExamService{}
+	columns := map[string]int{"类型": 0, "题目": 1, "选项": 2, "答案": 3}
+	records := [][]string{
+		{"选择题", "Go是什么？", "A. 编程语言\nB. 数据库\nC. 操作系统", "A"},
+		{"选择题", "Rust是什么？", "A. 编程语言\nB. 数据库", "A"},
+		{"选择题", "什么是CSS？", "A. 样式表语言,B. 编程语言,C. 数据库", "A"},
+	}
+
+	optionSeparator, answerSeparator, inferred := e.resolveAnswerSeparators(SeparatorAuto, SeparatorAuto, records, columns["选项"])
+	if optionSeparator != "\\n" || !inferred {
+		t.Fatalf("选项分隔符 = %q，推断 = %v", optionSeparator, inferred)
+	}
+	item := e.parseAnswerRecord(records[2], columns, optionSeparator, answerSeparator, inferred)
+	if want := []string{"A. 样式表语言", "B. 编程语言", "C. 数据库"}; !slices.Equal(item.Options, want) {
+		t.Errorf("选项 = %q，期望 %q", item.Options, want)
+	}
+	if !slices.Equal(item.Answer, []string{"A. 样式表语言"}) {
+		t.Errorf("答案 = %q", item.Answer)
+	}
+
+	// 指定了分隔符时不逐行改用其他分隔符
+	item = e.parseAnswerRecord(records[2], columns, "\\n", "\\n", false)
+	if len(item.Options) != 1 {
+		t.Errorf("选项 = %q，期望整格作为一个选项", item.Options)
+	}
+}
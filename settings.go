@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// settingsFileName 应用设置文件名
+const settingsFileName = "settings.json"
+
+// AppSettings 持久化的应用设置
+type AppSettings struct {
+	Matcher MatcherConfig `json:"matcher"` // 匹配算法参数
+
+	HeaderAliases  map[string][]string `json:"headerAliases,omitempty"`  // 用户自定义的标题别名，字段名 -> 别名列表
+	ColumnProfiles []ColumnProfile     `json:"columnProfiles,omitempty"` // 按题库来源保存的列映射方案
+	TypeAliases    map[string][]string `json:"typeAliases,omitempty"`    // 用户自定义的题型别名，标准题型 -> 别名列表
+}
+
+// defaultSettings 返回默认设置
+func defaultSettings() AppSettings {
+	return AppSettings{
+		Matcher: DefaultMatcherConfig(),
+	}
+}
+
+// 全局应用设置
+var (
+	settings   = defaultSettings()
+	settingsMu sync.RWMutex
+)
+
+// settingsPath 返回设置文件的完整路径
+func settingsPath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, settingsFileName), nil
+}
+
+// currentSettings 返回当前设置的副本
+func currentSettings() AppSettings {
+	settingsMu.RLock()
+	defer settingsMu.RUnlock()
+	return settings
+}
+
+// updateSettings 修改设置并写入磁盘，写入失败时内存中的设置保持不变
+func updateSettings(apply func(s *AppSettings) error) error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	next := settings
+	if err := apply(&next); err != nil {
+		return err
+	}
+
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码设置失败: %v", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+
+	settings = next
+	return nil
+}
+
+// loadSettings 启动时从磁盘加载设置，文件中缺失的字段使用默认值
+func loadSettings() error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取设置文件失败: %v", err)
+	}
+
+	loaded := defaultSettings()
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("解析设置文件失败: %v", err)
+	}
+	if err := loaded.Matcher.validate(); err != nil {
+		return fmt.Errorf("匹配参数无效: %v", err)
+	}
+
+	settingsMu.Lock()
+	settings = loaded
+	settingsMu.Unlock()
+	return nil
+}
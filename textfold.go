@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// tsCharactersData 繁体字到简体字的单字对照表
+//
+//go:embed data/ts_characters.txt
+var tsCharactersData string
+
+// traditionalToSimplified 繁体字到简体字的映射，启动时由tsCharactersData建立，之后只读
+var traditionalToSimplified = parseTSCharacters(tsCharactersData)
+
+// parseTSCharacters 解析繁简对照表，每行"繁体\t简体"，只接受单字到单字的映射
+func parseTSCharacters(data string) map[rune]rune {
+	table := map[rune]rune{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		from, to, ok := strings.Cut(line, "\t")
+		if !ok || utf8.RuneCountInString(from) != 1 || utf8.RuneCountInString(to) != 1 {
+			continue
+		}
+		f, _ := utf8.DecodeRuneInString(from)
+		t, _ := utf8.DecodeRuneInString(to)
+		table[f] = t
+	}
+	return table
+}
+
+// removedChars 标准化时移除的标点符号和特殊字符，但保留中文字符。
+// 这些字符在OCR识别中经常出现，但在语义匹配时应该被忽略
+var removedChars = map[rune]bool{
+	'(': true, ')': true, '[': true, ']': true, '{': true, '}': true,
+	'（': true, '）': true, '【': true, '】': true, '《': true, '》': true,
+	'"': true, '\'': true, '`': true, '~': true, '!': true, '@': true,
+	'#': true, '$': true, '%': true, '^': true, '&': true, '*': true,
+	'+': true, '=': true, '|': true, '\\': true, '/': true, '?': true,
+	'<': true, '>': true, ',': true, '.': true, ';': true, ':': true,
+	'、': true, '，': true, '。': true, '；': true, '：': true, '！': true,
+	'？': true, '…': true, '—': true, '－': true, '·': true,
+}
+
+// foldChar 将单个字符折叠为标准形式：全角字符转为半角（NFKC兼容分解），繁体字转为简体字。
+// 始终一对一地映射字符，保证标准化文本与原始文本的位置可以逐字对应；
+// NFKC分解为多个字符的（如"㎡"）保持原样
+func foldChar(char rune) rune {
+	if char < utf8.RuneSelf {
+		return char
+	}
+
+	// 中日韩统一表意文字没有兼容分解，跳过NFKC
+	if !(char >= 0x4E00 && char <= 0x9FFF) && !(char >= 0x3400 && char <= 0x4DBF) {
+		var buf [utf8.UTFMax]byte
+		n := utf8.EncodeRune(buf[:], char)
+		if !norm.NFKC.IsNormal(buf[:n]) {
+			folded := norm.NFKC.Bytes(buf[:n])
+			if r, size := utf8.DecodeRune(folded); size == len(folded) && r != utf8.RuneError {
+				char = r
+			}
+		}
+	}
+
+	if simplified, ok := traditionalToSimplified[char]; ok {
+		return simplified
+	}
+	return char
+}
+
+// normalizeWithPositions 标准化文本，同时返回标准化文本中每个字符在原始文本中的字符下标。
+// 依次进行字符折叠（全角空格由此转为半角空格）、移除特殊字符、合并连续空白、去除首尾空白
+func normalizeWithPositions(text string) (string, []int) {
+	var builder strings.Builder
+	builder.Grow(len(text))
+	positions := make([]int, 0, len(text))
+
+	pos := 0
+	lastSpace := true // 为true时丢弃空格，用于去除开头空白和合并连续空格
+	for _, char := range text {
+		original := char
+		char = foldChar(char)
+		switch {
+		case removedChars[original] || removedChars[char]:
+		case unicode.IsSpace(char) && lastSpace:
+		default:
+			builder.WriteRune(char)
+			positions = append(positions, pos)
+			lastSpace = unicode.IsSpace(char)
+		}
+		pos++
+	}
+
+	// 去除末尾空白
+	normalized := builder.String()
+	trimmed := strings.TrimRightFunc(normalized, unicode.IsSpace)
+	positions = positions[:len(positions)-utf8.RuneCountInString(normalized[len(trimmed):])]
+	return trimmed, positions
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 文本题库的两种写法
+const (
+	TextFormatPlain    = "text"     // 纯文本
+	TextFormatMarkdown = "markdown" // Markdown，切分前去掉标题、列表、引用和强调标记
+)
+
+var (
+	// markdownPrefixPattern 匹配行首的Markdown标记：引用、标题、无序列表和任务列表
+	markdownPrefixPattern = regexp.MustCompile(`^\s*(?:>\s*)*(?:#{1,6}\s+|[-*+]\s+(?:\[[ xX]\]\s+)?)?`)
+	// markdownEmphasisPattern 匹配加粗和行内代码标记。下划线常用作填空题的空缺，不作处理
+	markdownEmphasisPattern = regexp.MustCompile("\\*\\*|`")
+)
+
+// textFormatFromPath 根据扩展名判断文本写法，.md和.markdown为Markdown，其余按纯文本处理
+func textFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return TextFormatMarkdown
+	default:
+		return TextFormatPlain
+	}
+}
+
+// markdownToPlainLines 去掉每行的Markdown标记，保持行数不变以便报告行号与原文一致。
+// 代码块和分隔线按空行处理
+func markdownToPlainLines(lines []string) []string {
+	plain := make([]string, len(lines))
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || trimmed == "---" || trimmed == "***" || trimmed == "___" {
+			continue
+		}
+		line = markdownPrefixPattern.ReplaceAllString(line, "")
+		plain[i] = markdownEmphasisPattern.ReplaceAllString(line, "")
+	}
+	return plain
+}
+
+// ParseTextFile 按请求中的预设、切分规则和严格模式解析纯文本或Markdown题库，与PreviewTextFile使用相同的请求，
+// 试运行确认后可以原样导入。有问题的题目会被跳过，返回结果中的导入报告列出跳过的题目
+func (e *ExamService) ParseTextFile(req ParseTextRequest) (ImportResult, error) {
+	result, _, err := e.parseTextImport(req)
+	return result, err
+}
+
+// PreviewTextFile 试运行切分规则，返回前PreviewCount道题的原文和解析结果，不导入
+func (e *ExamService) PreviewTextFile(req ParseTextRequest) ([]ParsedQuestion, error) {
+	count := req.PreviewCount
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	_, parsed, err := e.parseTextImport(req)
+	if len(parsed) > count {
+		parsed = parsed[:count]
+	}
+	return parsed, err
+}
+
+// parseTextImport 按请求中的规则解析文本题库，返回导入结果和全部切分结果。
+// 文本来自Content（粘贴的内容）或FilePath，报告中的行号为文本中的行号
+func (e *ExamService) parseTextImport(req ParseTextRequest) (ImportResult, []ParsedQuestion, error) {
+	collector := newImportCollector(req.Strict)
+	matchers, err := resolveSegmentPatterns(req.Preset, req.Patterns)
+	if err != nil {
+		return collector.result, nil, err
+	}
+
+	content := req.Content
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if req.FilePath != "" {
+		data, err := os.ReadFile(req.FilePath)
+		if err != nil {
+			return collector.result, nil, fmt.Errorf("无法打开文件: %v", err)
+		}
+		// 按指定编码解码，auto时自动检测
+		decoded, usedEncoding, err := decodeText(data, req.Encoding)
+		if err != nil {
+			return collector.result, nil, err
+		}
+		content = decoded
+		collector.result.Encoding = usedEncoding
+		if format == "" {
+			format = textFormatFromPath(req.FilePath)
+		}
+	}
+	if strings.TrimSpace(content) == "" {
+		return collector.result, nil, fmt.Errorf("文本内容为空")
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	switch format {
+	case "", TextFormatPlain, "txt":
+	case TextFormatMarkdown, "md":
+		lines = markdownToPlainLines(lines)
+	default:
+		return collector.result, nil, fmt.Errorf("不支持的文本格式: %s，仅支持text和markdown", req.Format)
+	}
+
+	parsed := segmentQuestions(lines, matchers)
+	if len(parsed) == 0 {
+		return collector.result, parsed, fmt.Errorf("没有识别出题目，请检查题号规则")
+	}
+	result, err := collectParsedQuestions(parsed, req.Strict)
+	result.Encoding = collector.result.Encoding
+	result.setSource(req.FilePath)
+	return result, parsed, err
+}
+
+// ParseTextRequest 文本题库解析请求结构，HTTP接口和桌面端共用，FilePath和Content二选一
+type ParseTextRequest struct {
+	FilePath     string          `json:"filePath,omitempty"`
+	Content      string          `json:"content,omitempty"`      // 直接粘贴的文本
+	Encoding     string          `json:"encoding,omitempty"`     // 文件编码，auto或空时自动检测
+	Format       string          `json:"format,omitempty"`       // text或markdown，为空时按扩展名判断，粘贴的文本按纯文本处理
+	Preset       string          `json:"preset,omitempty"`       // 切分规则预设，为空时使用常见格式
+	Patterns     SegmentPatterns `json:"patterns"`               // 切分规则，不为空的字段覆盖预设中的规则
+	Strict       bool            `json:"strict,omitempty"`       // 严格模式：任意一题有问题即整体失败，默认跳过有问题的题目
+	PreviewCount int             `json:"previewCount,omitempty"` // HTTP接口中大于0时为试运行，只返回前N道题的原文和解析结果；PreviewTextFile中为0时预览默认数量
+}
+
+// ParseTextResponse HTTP文本题库解析响应结构
+type ParseTextResponse struct {
+	Success  bool             `json:"success"`
+	Message  string           `json:"message,omitempty"`
+	Encoding string           `json:"encoding,omitempty"` // 实际使用的文件编码
+	Results  []AnswerItem     `json:"results,omitempty"`  // 全部题目，试运行时不返回
+	Preview  []ParsedQuestion `json:"preview,omitempty"`  // 试运行时前N道题的切分结果
+	Report   *ImportReport    `json:"report,omitempty"`   // 全部文本的导入报告
+}
+
+// handleParseText 处理HTTP文本题库解析请求
+func handleParseText(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许POST方法
+	if r.Method != "POST" {
+		http.Error(w, "只支持POST方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 解析请求体
+	var req ParseTextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "请求体解析失败: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	result, parsed, err := examService.parseTextImport(req)
+	if req.PreviewCount > 0 && len(parsed) > req.PreviewCount {
+		parsed = parsed[:req.PreviewCount]
+	}
+	if err != nil {
+		response := ParseTextResponse{
+			Success:  false,
+			Message:  "文本解析失败: " + err.Error(),
+			Encoding: result.Encoding,
+			Report:   &result.Report,
+		}
+		// 试运行时即使有问题的题目也返回切分结果，便于调整规则
+		if req.PreviewCount > 0 {
+			response.Preview = parsed
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := ParseTextResponse{
+		Success:  true,
+		Message:  result.Report.Summary(),
+		Encoding: result.Encoding,
+		Report:   &result.Report,
+	}
+	if req.PreviewCount > 0 {
+		response.Preview = parsed
+	} else {
+		response.Results = result.Items
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleListSegmentPresets 处理HTTP获取切分规则预设请求
+func handleListSegmentPresets(w http.ResponseWriter, r *http.Request) {
+	// 设置CORS头
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	// 处理预检请求
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	// 只允许GET方法
+	if r.Method != "GET" {
+		http.Error(w, "只支持GET方法", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// 创建ExamService实例
+	examService := &ExamService{}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(examService.ListSegmentPresets())
+}
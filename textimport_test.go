@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseTextFileMarkdown(t *testing.T) {
+	content := "# 第一章\n\n" +
+		"## 一、单选题\n\n" +
+		"1. **光速**约为多少\n" +
+		"- A. 30万千米每秒\n" +
+		"- B. 340米每秒\n\n" +
+		"> 答案：A\n\n" +
+		"## 二、判断题\n\n" +
+		"2. 水在标准大气压下100℃沸腾\n\n" +
+		"答案：正确\n"
+	path := filepath.Join(t.TempDir(), "题库.md")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &ExamService{}
+	result, err := e.ParseTextFile(ParseTextRequest{FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("导入 %d 道题，期望 2 道: %+v", len(result.Items), result.Report)
+	}
+	first, second := result.Items[0], result.Items[1]
+	if first.Question != "光速约为多少" || first.Type != QuestionTypeSingle || !slices.Equal(first.AnswerKeys, []string{"A"}) {
+		t.Errorf("第1题 = %+v", first)
+	}
+	if second.Type != QuestionTypeJudge || !slices.Equal(second.Answer, []string{judgeTrue}) {
+		t.Errorf("第2题 = %+v", second)
+	}
+	if first.Source != "题库.md" {
+		t.Errorf("来源 = %q", first.Source)
+	}
+}
+
+func TestParseTextQAPreset(t *testing.T) {
+	req := ParseTextRequest{
+		Content: "1. 问：水的化学式是什么\n答：H2O\n\n问：光合作用发生在哪里\n答：叶绿体\n",
+		Preset:  "qa",
+	}
+	e := &ExamService{}
+	result, err := e.ParseTextFile(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ question, answer string }{{"水的化学式是什么", "H2O"}, {"光合作用发生在哪里", "叶绿体"}}
+	if len(result.Items) != len(want) {
+		t.Fatalf("导入 %d 道题，期望 %d 道: %+v", len(result.Items), len(want), result.Report)
+	}
+	for i, w := range want {
+		item := result.Items[i]
+		if item.Question != w.question || !slices.Equal(item.Answer, []string{w.answer}) {
+			t.Errorf("第%d题 = %q %q", i+1, item.Question, item.Answer)
+		}
+	}
+}
+
+func TestPreviewAndParseTextUseSameRules(t *testing.T) {
+	req := ParseTextRequest{
+		Content:  "Q1 天空是什么颜色\nA. 蓝色\nB. 绿色\n正解：A\n",
+		Patterns: SegmentPatterns{Question: `^Q\d+\s*`, Answer: `^正解[:：]\s*(.*)$`},
+		Strict:   true,
+	}
+	e := &ExamService{}
+	preview, err := e.PreviewTextFile(req)
+	if err != nil || len(preview) != 1 || preview[0].Issue != "" {
+		t.Fatalf("试运行结果 %+v, %v", preview, err)
+	}
+	result, err := e.ParseTextFile(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || !slices.Equal(result.Items[0].AnswerKeys, []string{"A"}) {
+		t.Errorf("导入结果 = %+v", result.Items)
+	}
+}